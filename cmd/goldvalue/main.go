@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"LoLItemRecommender/internal/gold"
+	"LoLItemRecommender/internal/printer"
+	"LoLItemRecommender/internal/riotapi/api"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+func analyze(sd *gamedata.StaticData, version string) (*gold.Analysis, error) {
+	items, err := sd.RetrieveItemsForVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return gold.Analyze(version, items), nil
+}
+
+func displayAnalysis(a *gold.Analysis) {
+	printer.Printf("{-F_CYAN,BOLD}Reference gold values for %s", a.Version)
+	stats := make([]string, 0, len(a.Reference))
+	for s := range a.Reference {
+		stats = append(stats, string(s))
+	}
+	sort.Strings(stats)
+	for _, s := range stats {
+		printer.Printf("  %-24s %.2f gold", s, a.Reference[gold.Stat(s)])
+	}
+	printer.Printf("{-F_CYAN,BOLD}Gold efficiency for %s", a.Version)
+	for _, e := range a.Sorted() {
+		unvalued := ""
+		if len(e.Unvalued) > 0 {
+			u := make([]string, 0, len(e.Unvalued))
+			for _, s := range e.Unvalued {
+				u = append(u, string(s))
+			}
+			unvalued = " {-F_YELLOW}(not valued: " + strings.Join(u, ", ") + ")"
+		}
+		printer.Printf("  %-32s %5d gold  {-BOLD}%6.1f%%{-RESET}%s", e.Item.Name, e.Item.Gold.Total, e.Percent, unvalued)
+	}
+}
+
+func displayChanges(changes []*gold.Change) {
+	for _, c := range changes {
+		switch {
+		case c.Before == nil:
+			printer.Printf("  {-F_GREEN}+ %-30s{-RESET} new, %.1f%%", c.Name, c.After.Percent)
+		case c.After == nil:
+			printer.Printf("  {-F_RED}- %-30s{-RESET} removed, was %.1f%%", c.Name, c.Before.Percent)
+		case c.Delta > 0:
+			printer.Printf("  {-F_GREEN}↑ %-30s{-RESET} %.1f%% -> %.1f%% (%+.1f)", c.Name, c.Before.Percent, c.After.Percent, c.Delta)
+		default:
+			printer.Printf("  {-F_RED}↓ %-30s{-RESET} %.1f%% -> %.1f%% (%+.1f)", c.Name, c.Before.Percent, c.After.Percent, c.Delta)
+		}
+	}
+}
+
+func main() {
+	from := flag.String("from", "", "DDragon version to compare from (default: the previous patch)")
+	to := flag.String("to", "", "DDragon version to analyze (default: the latest patch)")
+	threshold := flag.Float64("threshold", 1, "minimum efficiency change to display, in percentage points")
+	flag.Parse()
+
+	client := api.NewClient()
+	sd := gamedata.NewStaticData(api.NewEndpointsManager(os.Getenv("RIOT_API_KEY"), "euw1"), client)
+	versions, err := sd.RetrieveAPIVersions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *to == "" {
+		*to = versions[0]
+	}
+	if *from == "" && len(versions) > 1 {
+		*from = versions[1]
+	}
+
+	after, err := analyze(sd, *to)
+	if err != nil {
+		log.Fatal(err)
+	}
+	displayAnalysis(after)
+	if *from == "" {
+		return
+	}
+	before, err := analyze(sd, *from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printer.Printf("{-F_CYAN,BOLD}Gold efficiency changes from %s to %s", *from, *to)
+	displayChanges(gold.Significant(gold.Compare(before, after), *threshold))
+}
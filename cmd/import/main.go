@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"time"
+
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/crawler"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/importer"
+	"LoLItemRecommender/internal/riotapi/api"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+func main() {
+	workers := flag.Int("workers", runtime.NumCPU(), "number of matches decoded and saved in parallel")
+	interval := flag.Duration("progress", 5*time.Second, "interval between the progress reports")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <directory|file|tarball>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Static data come from DDragon, no Riot API call is made
+	sd, err := gamedata.LoadStaticData(api.NewEndpointsManager("", cfg.Platforms[0]), api.NewClient(), "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	f, err := crawler.NewFilter(cfg, sd)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := database.NewDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateTables(); err != nil {
+		log.Fatal(err)
+	}
+
+	imp := importer.New(db, sd, f)
+	imp.Workers = *workers
+	imp.Interval = *interval
+	r := imp.Run(func(out chan<- importer.Payload, r *importer.Report) {
+		for _, path := range flag.Args() {
+			if err := importer.Walk(path, out, r.Skip); err != nil {
+				r.Skip(path, err)
+			}
+		}
+	})
+	r.Print()
+}
@@ -0,0 +1,83 @@
+// Command reprocess rebuilds the relational tables from the raw matches
+// archived by the retriever, to backfill new columns and tables without
+// downloading anything.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"LoLItemRecommender/internal/archive"
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/crawler"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/importer"
+	"LoLItemRecommender/internal/riotapi/api"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	dir := flag.String("archive", cfg.ArchiveDir, "directory of the archived matches")
+	platform := flag.String("platform", "", "reprocess the matches of this platform only, e.g. euw1")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of matches decoded and saved in parallel")
+	interval := flag.Duration("progress", 5*time.Second, "interval between the progress reports")
+	flag.Parse()
+	if *dir == "" {
+		log.Fatal("no archive directory given")
+	}
+	if _, err := os.Stat(*dir); err != nil {
+		log.Fatal(err)
+	}
+	store, err := archive.New(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sd, err := gamedata.LoadStaticData(api.NewEndpointsManager("", cfg.Platforms[0]), api.NewClient(), "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	f, err := crawler.NewFilter(cfg, sd)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := database.NewDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateTables(); err != nil {
+		log.Fatal(err)
+	}
+
+	prefix := strings.ToUpper(*platform) + "_"
+	imp := importer.New(db, sd, f)
+	imp.Workers = *workers
+	imp.Interval = *interval
+	r := imp.Run(func(out chan<- importer.Payload, r *importer.Report) {
+		err := store.Walk(func(matchID, path string) error {
+			if *platform != "" && !strings.HasPrefix(matchID, prefix) {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				r.Skip(path, err)
+				return nil
+			}
+			out <- importer.Payload{Name: matchID, Data: data}
+			return nil
+		})
+		if err != nil {
+			r.Skip(store.Root(), err)
+		}
+	})
+	r.Print()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"LoLItemRecommender/internal/crawler"
+	"LoLItemRecommender/internal/printer"
+	"LoLItemRecommender/internal/queue"
+)
+
+const (
+	refreshInterval = time.Second
+	// Interval between the summary lines when the output isn't a terminal
+	summaryInterval = 30 * time.Second
+	maxErrorLength  = 100
+	// Warnings and errors shown under the dashboard, the log being silenced
+	recentErrors = 5
+)
+
+type crawlView struct {
+	gd   *crawler.GameData
+	pool *queue.Pool
+}
+
+// dashboard shows the progress of the crawls, refreshed in place when the
+// output is a terminal, as periodic summary lines otherwise.
+type dashboard struct {
+	views []crawlView
+	tty   bool
+	// Number of lines drawn by the last refresh
+	lines int
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func newDashboard(out *os.File, views []crawlView) *dashboard {
+	return &dashboard{views: views, tty: isTerminal(out)}
+}
+
+// run refreshes the dashboard until done is closed, then draws it one last
+// time. The log messages are silenced meanwhile on a terminal, so they don't
+// scroll the dashboard away, the latest warnings and errors being shown under
+// it.
+func (d *dashboard) run(done <-chan struct{}) {
+	interval := summaryInterval
+	if d.tty {
+		level := printer.GetLogLevel()
+		printer.SetLogLevel(printer.LevelSilent)
+		printer.KeepRecent(recentErrors)
+		defer func() {
+			printer.SetLogLevel(level)
+			printer.KeepRecent(0)
+		}()
+		interval = refreshInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			d.refresh()
+		case <-done:
+			d.refresh()
+			return
+		}
+	}
+}
+
+func (d *dashboard) refresh() {
+	if !d.tty {
+		for _, v := range d.views {
+			d.summary(v)
+		}
+		return
+	}
+	lines := make([]string, 0, len(d.views)*5+recentErrors)
+	for _, v := range d.views {
+		lines = append(lines, d.draw(v)...)
+	}
+	for _, msg := range printer.Recent() {
+		lines = append(lines, "{-F_RED}"+truncate(msg)+"{-RESET}")
+	}
+	// Back to the top of the previous drawing, cleared to the end of the screen
+	if d.lines > 0 {
+		lines[0] = fmt.Sprintf("\x1b[%dA\x1b[J", d.lines) + lines[0]
+	}
+	for _, l := range lines {
+		printer.Printf("%s", l)
+	}
+	d.lines = len(lines)
+}
+
+func formatErrors(p crawler.Progress) string {
+	if len(p.Errors) == 0 {
+		return "none"
+	}
+	kinds := make([]string, 0, len(p.Errors))
+	for k := range p.Errors {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	counts := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		counts = append(counts, fmt.Sprintf("%s %d", k, p.Errors[k]))
+	}
+	return strings.Join(counts, ", ")
+}
+
+func formatBudget(p crawler.Progress) string {
+	switch {
+	case p.StopReason != "":
+		return "stopping, " + p.StopReason
+	case !p.HasBudget:
+		return "no budget"
+	case p.ETA() == 0:
+		return fmt.Sprintf("%.0f%%", p.Completion*100)
+	}
+	return fmt.Sprintf("%.0f%%, ETA %s", p.Completion*100, p.ETA().Round(time.Second))
+}
+
+func (d *dashboard) draw(v crawlView) []string {
+	p := v.gd.Progress()
+	minW, maxW := v.pool.Bounds()
+	lines := []string{
+		fmt.Sprintf("{-F_CYAN,BOLD}[%s]{-RESET} running for %s, budget: {-BOLD}%s{-RESET}",
+			p.Platform, p.Elapsed.Round(time.Second), formatBudget(p)),
+		fmt.Sprintf("  players visited {-BOLD}%d{-RESET}  frontier {-BOLD}%d{-RESET} (%d deferred to disk)  workers {-BOLD}%d/%d{-RESET} (%d-%d)",
+			p.Players, v.pool.Pending(), v.pool.Deferred(), v.pool.ActiveWorkers(), v.pool.Cap(), minW, maxW),
+		fmt.Sprintf("  matches fetched {-BOLD}%d{-RESET}  saved {-F_GREEN,BOLD}%d{-RESET}  rejected {-BOLD}%d{-RESET}  skipped {-BOLD}%d{-RESET}",
+			p.Fetched, p.Saved, p.Rejected, p.Skipped),
+		fmt.Sprintf("  API calls {-BOLD}%d{-RESET}  rate limit wait {-BOLD}%s{-RESET}", p.Calls, p.Waited.Round(time.Second)),
+		fmt.Sprintf("  errors: {-F_RED}%s{-RESET}", formatErrors(p)),
+	}
+	if p.LastError != nil {
+		lines = append(lines, "  last error: "+truncate(p.LastError.Error()))
+	}
+	return lines
+}
+
+// truncate shortens the message to a line of the dashboard.
+func truncate(msg string) string {
+	msg = strings.ReplaceAll(msg, "\n", " ")
+	if r := []rune(msg); len(r) > maxErrorLength {
+		return string(r[:maxErrorLength]) + "..."
+	}
+	return msg
+}
+
+func (d *dashboard) summary(v crawlView) {
+	p := v.gd.Progress()
+	printer.Info("{-F_CYAN}[%s]{-RESET} players %d, frontier %d (%d deferred), workers %d/%d, matches fetched %d saved %d rejected %d skipped %d, API calls %d (waited %s), errors: %s, budget: %s",
+		p.Platform, p.Players, v.pool.Pending(), v.pool.Deferred(), v.pool.ActiveWorkers(), v.pool.Cap(),
+		p.Fetched, p.Saved, p.Rejected, p.Skipped, p.Calls, p.Waited.Round(time.Second), formatErrors(p), formatBudget(p))
+}
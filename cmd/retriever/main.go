@@ -3,93 +3,214 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
+	"LoLItemRecommender/internal/archive"
+	"LoLItemRecommender/internal/config"
 	"LoLItemRecommender/internal/crawler"
 	"LoLItemRecommender/internal/database"
 	"LoLItemRecommender/internal/printer"
 	"LoLItemRecommender/internal/queue"
+	"LoLItemRecommender/internal/riotapi/api"
 	"LoLItemRecommender/internal/riotapi/gamedata"
 )
 
-var ErrNoAPIKey = errors.New("no riot api key set")
+var (
+	ErrNoAPIKey       = errors.New("no riot api key set")
+	ErrRefreshNoState = errors.New("can't refresh without a mysql sink, no player is known")
+)
 
-func getInitialPlayers(db *database.DB, gd *crawler.GameData) ([]*gamedata.Player, error) {
-	players, err := db.GetLatestCrawledPlayers()
-	if err != nil {
-		return nil, err
-	}
-	if len(players) == 0 {
-		return gd.InitWithChallengerPlayers()
-	}
-	excludedNames := make([]string, 0, len(players))
-	for _, p := range players {
-		excludedNames = append(excludedNames, p.SummonerName)
+func handleErrors(p *queue.Pool, gd *crawler.GameData) {
+	for e := range p.GetErrorsChan() {
+		gd.RecordError(e)
+		printer.Error("{-F_RED}[%s] Error received '%s'", gd.Platform(), e.Error())
 	}
-	playersCrawled, err := db.GetAllPlayerNameCrawledExcept(excludedNames)
-	if err != nil {
-		return nil, err
-	}
-	gd.FlagPlayers(playersCrawled)
-	return players, nil
+	printer.Debug("Stopped chan err of %s", gd.Platform())
 }
 
-func handleErrorsAndSignals(p *queue.Pool, ctx context.Context, cancel context.CancelFunc, signalChan chan os.Signal) {
-	for {
+// crawl crawls the platform from the players until the frontier is empty or
+// its budget is reached. In refresh mode, only the new matches of the players
+// are crawled. When the context is done, the running jobs have grace to
+// finish before being cancelled.
+func crawl(ctx context.Context, gd *crawler.GameData, p *queue.Pool, players []*gamedata.Player, refresh bool, grace time.Duration) {
+	errorsDone := make(chan struct{})
+	go func() {
+		handleErrors(p, gd)
+		close(errorsDone)
+	}()
+	completed := make(chan struct{})
+	go func() {
 		select {
-		case e := <-p.GetErrorsChan():
-			printer.Error("{-F_RED}Error received '%signalChan'", e.Error())
 		case <-ctx.Done():
-			printer.Debug("Stopped chan err")
-			return
-		case <-signalChan:
-			printer.Info("Signal received, closing all")
-			p.Close()
-			cancel()
-			return
+			sctx, cancel := context.WithTimeout(context.Background(), grace)
+			defer cancel()
+			if err := p.Shutdown(sctx); err != nil {
+				printer.Warn("Jobs of %s cancelled after %s", gd.Platform(), grace)
+			}
+		case <-completed:
 		}
+	}()
+
+	kind := crawler.JobCrawlPlayer
+	if refresh {
+		kind = crawler.JobRefreshPlayer
 	}
+	for _, player := range players {
+		printer.Debug("Dispatch for player {-F_YELLOW}%s", player.SummonerName)
+		j, err := gd.PlayerJob(kind, player)
+		if err != nil {
+			printer.Error("Unable to dispatch the crawl of %s: %v", player.SummonerName, err)
+			continue
+		}
+		// Waits for the workers when the queue is full
+		if err := p.Submit(ctx, j); err != nil {
+			break
+		}
+	}
+	p.WaitJobsToComplete()
+	close(completed)
+	// Waits for the shutdown started by the context, if any
+	p.Shutdown(context.Background())
+	<-errorsDone
+	printer.Info("Jobs of %s completed", gd.Platform())
 }
 
 func main() {
-	db, err := database.NewDB()
-	if err != nil {
-		log.Fatal(err)
+	refresh := flag.Bool("refresh", false, "crawl the new matches of the known players instead of the frontier")
+	grace := flag.Duration("grace", 5*time.Second, "time given to the running jobs to finish when the crawl is interrupted")
+	minWorkers := flag.Int("min-workers", 1, "minimum number of workers of each platform")
+	maxWorkers := flag.Int("max-workers", 20, "maximum number of workers of each platform")
+	scaleInterval := flag.Duration("scale-interval", 10*time.Second, "interval between the adjustments of the number of workers")
+	spillDir := flag.String("spill-dir", os.TempDir(), "directory the queued jobs beyond the in-memory limit are written to")
+	flag.Parse()
+
+	if os.Getenv("RIOT_API_KEY") == "" {
+		log.Fatal(ErrNoAPIKey)
 	}
-	if err := db.CreateTables(); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
 		log.Fatal(err)
 	}
-	if os.Getenv("RIOT_API_KEY") == "" {
-		log.Fatal(ErrNoAPIKey)
+	var (
+		db    *database.DB
+		state crawler.StateStore = crawler.NewMemoryState()
+	)
+	if cfg.UsesSink(config.SinkMySQL) {
+		if db, err = database.NewDB(); err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+		if err := db.CreateTables(); err != nil {
+			log.Fatal(err)
+		}
+		state = db
+	} else {
+		if *refresh {
+			log.Fatal(ErrRefreshNoState)
+		}
+		printer.Warn("No mysql sink, the crawl won't be resumable")
 	}
-	gd, err := crawler.NewGameData("euw1", db)
+	sink, err := crawler.NewSink(cfg.Sinks, db)
 	if err != nil {
 		log.Fatal(err)
 	}
-	players, err := getInitialPlayers(db, gd)
+	defer sink.Close()
+	var store *archive.Store
+	if cfg.ArchiveDir != "" {
+		if store, err = archive.New(cfg.ArchiveDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+	em := api.NewEndpointsManager(os.Getenv("RIOT_API_KEY"), cfg.Platforms[0])
+	sd, err := gamedata.LoadStaticData(em, api.NewClient(), "")
 	if err != nil {
 		log.Fatal(err)
 	}
-	p := queue.NewPool(queue.CalculatePoolCap(players))
+	static := gamedata.NewWatcher(sd, gamedata.DefaultWatchInterval)
+
+	views := make([]crawlView, 0, len(cfg.Platforms))
+	frontiers := make([][]*gamedata.Player, 0, len(cfg.Platforms))
+	for _, platform := range cfg.Platforms {
+		gd, err := crawler.NewGameData(platform, state, sink, cfg, static)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if store != nil {
+			gd.SetArchive(store)
+		}
+		resume := gd.ResumeCrawl
+		if *refresh {
+			resume = gd.RefreshPlayers
+		}
+		players, err := resume()
+		if err != nil {
+			log.Fatal(err)
+		}
+		pool := queue.NewPool(queue.CalculatePoolCap(players))
+		if err := pool.SetBounds(*minWorkers, *maxWorkers); err != nil {
+			log.Fatal(err)
+		}
+		if err := pool.SpillTo(*spillDir); err != nil {
+			log.Fatal(err)
+		}
+		go pool.AutoScale(*scaleInterval, gd.SampleLoad)
+		gd.RegisterJobs(pool)
+		views = append(views, crawlView{gd: gd, pool: pool})
+		frontiers = append(frontiers, players)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	signalChan := make(chan os.Signal)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGABRT, syscall.SIGKILL, syscall.SIGQUIT)
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGABRT, syscall.SIGQUIT)
+	go func() {
+		select {
+		case <-signalChan:
+			printer.Info("Signal received, closing all")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go crawler.WatchPatches(ctx, static, state)
 
-	go handleErrorsAndSignals(p, ctx, cancel, signalChan)
+	out := os.Stdout
+	if cfg.UsesSink(config.SinkStdout) {
+		out = os.Stderr
+	}
+	dashboardDone := make(chan struct{})
+	dashboardStopped := make(chan struct{})
+	go func() {
+		newDashboard(out, views).run(dashboardDone)
+		close(dashboardStopped)
+	}()
 
-	for _, player := range players {
-		printer.Debug("Dispatch for player {-F_YELLOW}%s", player.SummonerName)
-		p.Dispatch(func() error {
-			return gd.CrawlPlayerData(player, p)
-		})
+	var wg sync.WaitGroup
+	for i, v := range views {
+		wg.Add(1)
+		go func(v crawlView, players []*gamedata.Player) {
+			defer wg.Done()
+			crawl(ctx, v.gd, v.pool, players, *refresh, *grace)
+		}(v, frontiers[i])
 	}
-	p.WaitJobsToComplete()
+	wg.Wait()
 	cancel()
+	close(dashboardDone)
+	<-dashboardStopped
 	printer.Info("Jobs completed, channels closed")
+	for _, v := range views {
+		v.gd.PrintSummary()
+	}
+	// Without database the dead letters would be lost silently
+	if m, ok := state.(*crawler.MemoryState); ok {
+		for _, f := range m.FailedMatches() {
+			printer.Warn("Game %s failed %d times (%s): %s", f.MatchUID, f.Attempts, f.Kind, f.Error)
+		}
+	}
 }
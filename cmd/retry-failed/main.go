@@ -0,0 +1,138 @@
+// Command retry-failed processes the dead letters of the crawl again, the
+// matches which couldn't be downloaded or saved, waiting longer after each
+// failed attempt.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/crawler"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/printer"
+	"LoLItemRecommender/internal/riotapi/api"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+var ErrNoAPIKey = errors.New("no riot api key set")
+
+// nextAttempt returns when the dead letter can be retried, the delay doubling
+// after each attempt.
+func nextAttempt(f database.FailedMatch, backoff time.Duration) time.Time {
+	return f.LastFailedAt.Add(backoff << (f.Attempts - 1))
+}
+
+// retryDue retries the dead letters which are due, and returns the time of the
+// next one, zero if there is none left.
+func retryDue(ctx context.Context, db *database.DB, crawlers map[string]*crawler.GameData, platform string, maxAttempts int, backoff time.Duration) (time.Time, error) {
+	failed, err := db.GetFailedMatches(platform)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var next time.Time
+	for _, f := range failed {
+		if ctx.Err() != nil {
+			return time.Time{}, ctx.Err()
+		}
+		if f.Attempts >= maxAttempts {
+			continue
+		}
+		gd, ok := crawlers[f.Platform]
+		if !ok {
+			printer.Warn("Game %s of %s skipped, the platform isn't configured", f.MatchUID, f.Platform)
+			continue
+		}
+		if at := nextAttempt(f, backoff); at.After(time.Now()) {
+			if next.IsZero() || at.Before(next) {
+				next = at
+			}
+			continue
+		}
+		if err := gd.RetryMatch(ctx, f.MatchUID); err != nil {
+			printer.Warn("Game %s failed again, attempt %d/%d: %v", f.MatchUID, f.Attempts+1, maxAttempts, err)
+			if f.Attempts+1 < maxAttempts {
+				next = time.Now()
+			}
+			continue
+		}
+		printer.Info("{-F_GREEN,BOLD}Game %s recovered", f.MatchUID)
+	}
+	return next, nil
+}
+
+func main() {
+	platform := flag.String("platform", "", "retry the dead letters of this platform only, e.g. euw1")
+	maxAttempts := flag.Int("max-attempts", 5, "number of attempts after which a dead letter is given up")
+	backoff := flag.Duration("backoff", 30*time.Second, "delay before the first retry, doubled after each attempt")
+	flag.Parse()
+
+	if os.Getenv("RIOT_API_KEY") == "" {
+		log.Fatal(ErrNoAPIKey)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := database.NewDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateTables(); err != nil {
+		log.Fatal(err)
+	}
+	sink, err := crawler.NewSink(cfg.Sinks, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sink.Close()
+	sd, err := gamedata.LoadStaticData(api.NewEndpointsManager(os.Getenv("RIOT_API_KEY"), cfg.Platforms[0]), api.NewClient(), "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	static := gamedata.NewWatcher(sd, gamedata.DefaultWatchInterval)
+	crawlers := make(map[string]*crawler.GameData, len(cfg.Platforms))
+	for _, p := range cfg.Platforms {
+		if crawlers[p], err = crawler.NewGameData(p, db, sink, cfg, static); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	for {
+		next, err := retryDue(ctx, db, crawlers, *platform, *maxAttempts, *backoff)
+		if ctx.Err() != nil {
+			printer.Info("Signal received, stopping")
+			return
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if next.IsZero() {
+			break
+		}
+		printer.Info("Waiting until %s for the next retry", next.Format(time.TimeOnly))
+		select {
+		case <-time.After(time.Until(next)):
+		case <-ctx.Done():
+			printer.Info("Signal received, stopping")
+			return
+		}
+	}
+	failed, err := db.GetFailedMatches(*platform)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range failed {
+		printer.Warn("Game %s given up after %d attempts (%s): %s", f.MatchUID, f.Attempts, f.Kind, f.Error)
+	}
+	printer.Info("No dead letter left to retry, {-F_MAGENTA,BOLD}%d {-RESET}given up", len(failed))
+}
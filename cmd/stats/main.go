@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"LoLItemRecommender/internal/config"
 	"LoLItemRecommender/internal/database"
 	"LoLItemRecommender/internal/printer"
 	"LoLItemRecommender/internal/riotapi/api"
@@ -45,20 +46,22 @@ func main() {
 
 	client := api.NewClient()
 	em := api.NewEndpointsManager(os.Getenv("RIOT_API_KEY"), "euw1")
-	staticData := gamedata.NewStaticData(em, client)
-	if err := staticData.RetrieveAPIVersion(); err != nil {
-		log.Fatal(err)
-	}
-	if err := staticData.RetrieveChampionsStats(); err != nil {
-		log.Fatal(err)
-	}
-	if err := staticData.RetrieveItems(); err != nil {
+	staticData, err := gamedata.LoadStaticData(em, client, "")
+	if err != nil {
 		log.Fatal(err)
 	}
 
-	signalChan := make(chan os.Signal)
+	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGABRT, syscall.SIGKILL, syscall.SIGQUIT)
 	go cancelUI(ctx, cancel, signalChan)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
 	c := ui.NewConsole(ctx)
+	defer c.Close()
+	if len(cfg.TrackedChampions) > 0 {
+		c.SetTarget(staticData.GetChampionStats(cfg.TrackedChampions[0].Name))
+	}
 	c.AskUserChampions(staticData, db)
 }
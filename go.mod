@@ -3,13 +3,17 @@ module LoLItemRecommender
 go 1.20
 
 require (
+	github.com/go-sql-driver/mysql v1.7.0
 	github.com/google/uuid v1.3.0
 	github.com/jmoiron/sqlx v1.3.5
+	github.com/peterh/liner v1.2.2
+	github.com/redis/go-redis/v9 v9.0.3
+	golang.org/x/text v0.14.0
 )
 
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/go-sql-driver/mysql v1.7.0 // indirect
-	github.com/redis/go-redis/v9 v9.0.3 // indirect
+	github.com/mattn/go-runewidth v0.0.3 // indirect
+	golang.org/x/sys v0.5.0 // indirect
 )
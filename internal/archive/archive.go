@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const extension = ".json.gz"
+
+var ErrInvalidMatchID = errors.New("invalid match ID")
+
+// Store keeps the raw payloads of the matches, gzipped, one file per match.
+// The path of a match is derived from its ID, e.g.
+// <root>/EUW1/3f/EUW1_6543210987.json.gz, so a match is stored only once.
+type Store struct {
+	root string
+}
+
+func New(root string) (*Store, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{root: root}, nil
+}
+
+// Root returns the directory of the archive.
+func (s *Store) Root() string {
+	return s.root
+}
+
+func (s *Store) path(matchID string) (string, error) {
+	platform, _, ok := strings.Cut(matchID, "_")
+	if !ok || platform == "" || strings.ContainsAny(matchID, `/\.`) {
+		return "", ErrInvalidMatchID
+	}
+	h := sha1.Sum([]byte(matchID))
+	return filepath.Join(s.root, platform, hex.EncodeToString(h[:1]), matchID+extension), nil
+}
+
+// Has reports whether the payload of the match is archived.
+func (s *Store) Has(matchID string) bool {
+	p, err := s.path(matchID)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(p)
+	return err == nil
+}
+
+// Put archives the raw payload of the match, unless it's already archived.
+func (s *Store) Put(matchID string, raw []byte) error {
+	p, err := s.path(matchID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(p); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(raw); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	// Written aside then renamed, so a payload is never read half written
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+matchID+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Get returns the raw payload of the match.
+func (s *Store) Get(matchID string) ([]byte, error) {
+	p, err := s.path(matchID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+// Walk calls fn with the ID and the path of the gzipped payload of every
+// archived match.
+func (s *Store) Walk(fn func(matchID, path string) error) error {
+	return filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, extension) {
+			return nil
+		}
+		return fn(strings.TrimSuffix(name, extension), p)
+	})
+}
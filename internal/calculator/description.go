@@ -0,0 +1,63 @@
+package calculator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DescriptionStats holds the item stats DDragon only exposes in the item
+// description, not in the stats field.
+type DescriptionStats struct {
+	Lethality        float64
+	ArmorPenetration float64
+	MagicPenetration float64
+	MagicPenPercent  float64
+	AbilityHaste     float64
+	Omnivamp         float64
+	CritDamage       float64
+}
+
+var (
+	statsBlockRegex = regexp.MustCompile(`(?s)<stats>(.*?)</stats>`)
+	statLineRegex   = regexp.MustCompile(`<attention>\s*([\d.]+)(%?)\s*</attention>\s*([A-Za-z ]+)`)
+)
+
+// ParseDescriptionStats extracts the stats listed in the <stats> block of an
+// item description.
+func ParseDescriptionStats(description string) DescriptionStats {
+	var ds DescriptionStats
+	block := statsBlockRegex.FindStringSubmatch(description)
+	if block == nil {
+		return ds
+	}
+	for _, m := range statLineRegex.FindAllStringSubmatch(block[1], -1) {
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		percent := m[2] == "%"
+		if percent {
+			v /= 100
+		}
+		switch strings.ToLower(strings.TrimSpace(m[3])) {
+		case "lethality":
+			ds.Lethality += v
+		case "armor penetration":
+			ds.ArmorPenetration += v
+		case "magic penetration":
+			if percent {
+				ds.MagicPenPercent += v
+			} else {
+				ds.MagicPenetration += v
+			}
+		case "ability haste":
+			ds.AbilityHaste += v
+		case "omnivamp":
+			ds.Omnivamp += v
+		case "critical strike damage":
+			ds.CritDamage += v
+		}
+	}
+	return ds
+}
@@ -0,0 +1,139 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+const (
+	MinLevel = 1
+	MaxLevel = 18
+
+	// Base critical strike damage multiplier.
+	baseCritDamage = 1.75
+	maxAttackSpeed = 2.5
+)
+
+var ErrInvalidLevel = errors.New("level must be between 1 and 18")
+
+// Sheet is the full stat sheet of a champion at a given level with a set of items.
+// Percentages are expressed as ratios (0.2 for 20%).
+type Sheet struct {
+	Level             int
+	HP                float64
+	HPRegen           float64
+	Mana              float64
+	ManaRegen         float64
+	Armor             float64
+	BonusArmor        float64
+	MagicResist       float64
+	BonusMagicResist  float64
+	AttackDamage      float64
+	BaseAttackDamage  float64
+	BonusAttackDamage float64
+	AbilityPower      float64
+	AttackSpeed       float64
+	BonusAttackSpeed  float64
+	AttackRange       float64
+	MoveSpeed         float64
+	CritChance        float64
+	CritDamage        float64
+	LifeSteal         float64
+	Omnivamp          float64
+	Lethality         float64
+	ArmorPenetration  float64
+	MagicPenetration  float64
+	MagicPenPercent   float64
+	AbilityHaste      float64
+}
+
+// growth returns the multiplier applied to per-level stats, following Riot's
+// formula: (level - 1) * (0.7025 + 0.0175 * (level - 1)).
+func growth(level int) float64 {
+	n := float64(level - 1)
+	return n * (0.7025 + 0.0175*n)
+}
+
+func grow(base, perLevel float64, level int) float64 {
+	return base + perLevel*growth(level)
+}
+
+// ComputeSheet returns the stats of the champion at the given level, adding
+// the stats of the given items.
+func ComputeSheet(champion *gamedata.ChampionStats, level int, items []*gamedata.ItemData) (*Sheet, error) {
+	if level < MinLevel || level > MaxLevel {
+		return nil, ErrInvalidLevel
+	}
+	cs := champion.Stats
+	s := &Sheet{
+		Level:            level,
+		HP:               grow(float64(cs.Hp), cs.Hpperlevel, level),
+		HPRegen:          grow(cs.Hpregen, cs.Hpregenperlevel, level),
+		Mana:             grow(float64(cs.Mp), cs.Mpperlevel, level),
+		ManaRegen:        grow(cs.Mpregen, cs.Mpregenperlevel, level),
+		Armor:            grow(float64(cs.Armor), cs.Armorperlevel, level),
+		MagicResist:      grow(float64(cs.Spellblock), cs.Spellblockperlevel, level),
+		BaseAttackDamage: grow(float64(cs.Attackdamage), cs.Attackdamageperlevel, level),
+		AttackRange:      float64(cs.Attackrange),
+		CritDamage:       baseCritDamage,
+		// DDragon gives the attack speed growth as a percentage.
+		BonusAttackSpeed: cs.Attackspeedperlevel / 100 * growth(level),
+	}
+
+	var (
+		flatMoveSpeed    float64
+		percentMoveSpeed float64
+	)
+	for _, item := range items {
+		is := item.Stats
+		s.HP += is.FlatHPPoolMod
+		s.Mana += is.FlatMPPoolMod
+		s.HPRegen += is.FlatHPRegenMod
+		s.ManaRegen += is.FlatMPRegenMod
+		s.BonusArmor += is.FlatArmorMod
+		s.BonusMagicResist += is.FlatSpellBlockMod
+		s.BonusAttackDamage += is.FlatPhysicalDamageMod
+		s.AbilityPower += is.FlatMagicDamageMod
+		s.BonusAttackSpeed += is.PercentAttackSpeedMod
+		s.CritChance += is.FlatCritChanceMod
+		s.LifeSteal += is.PercentLifeStealMod
+		flatMoveSpeed += is.FlatMovementSpeedMod
+		percentMoveSpeed += is.PercentMovementSpeedMod
+
+		ds := ParseDescriptionStats(item.Description)
+		s.Lethality += ds.Lethality
+		s.ArmorPenetration = stackPenetration(s.ArmorPenetration, ds.ArmorPenetration)
+		s.MagicPenetration += ds.MagicPenetration
+		s.MagicPenPercent = stackPenetration(s.MagicPenPercent, ds.MagicPenPercent)
+		s.AbilityHaste += ds.AbilityHaste
+		s.Omnivamp += ds.Omnivamp
+		s.CritDamage += ds.CritDamage
+	}
+
+	s.Armor += s.BonusArmor
+	s.MagicResist += s.BonusMagicResist
+	s.AttackDamage = s.BaseAttackDamage + s.BonusAttackDamage
+	s.CritChance = math.Min(s.CritChance, 1)
+	s.MoveSpeed = (float64(cs.Movespeed) + flatMoveSpeed) * (1 + percentMoveSpeed)
+	// DDragon doesn't expose the attack speed ratio, the base attack speed is
+	// the ratio for the vast majority of champions.
+	s.AttackSpeed = math.Min(cs.Attackspeed*(1+s.BonusAttackSpeed), maxAttackSpeed)
+	return s, nil
+}
+
+// ComputeSheets returns the stat sheets for every level from 1 to 18.
+func ComputeSheets(champion *gamedata.ChampionStats, items []*gamedata.ItemData) []*Sheet {
+	sheets := make([]*Sheet, 0, MaxLevel)
+	for l := MinLevel; l <= MaxLevel; l++ {
+		s, _ := ComputeSheet(champion, l, items)
+		sheets = append(sheets, s)
+	}
+	return sheets
+}
+
+// stackPenetration combines percentage penetrations multiplicatively.
+func stackPenetration(current, added float64) float64 {
+	return 1 - (1-current)*(1-added)
+}
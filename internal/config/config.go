@@ -0,0 +1,201 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"LoLItemRecommender/internal/riotapi/api"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// DefaultPath is the configuration file read when LOL_CONFIG isn't set.
+const DefaultPath = "config.json"
+
+// DefaultArchiveDir is the directory of the raw matches payloads.
+const DefaultArchiveDir = "archive"
+
+var (
+	ErrNoTrackedChampion = errors.New("no tracked champion configured, set trackedChampions or allChampions")
+	// The budget matchesPerChampion counts the tracked champions only
+	ErrNoChampionBudget = errors.New("budget matchesPerChampion set without tracked champions")
+)
+
+type TrackedChampion struct {
+	Name string `json:"name"`
+	// Positions in which the champion games are kept, any position if empty:
+	// TOP, JUNGLE, MIDDLE, BOTTOM or UTILITY.
+	Roles []string `json:"roles"`
+}
+
+// Budget holds the stop conditions of the crawl of each platform, a zero
+// value meaning no limit.
+type Budget struct {
+	MaxMatches  int `json:"maxMatches"`
+	MaxAPICalls int `json:"maxApiCalls"`
+	MaxPlayers  int `json:"maxPlayers"`
+	// Wall-clock duration of the crawl, e.g. "2h30m".
+	Duration string `json:"duration"`
+	// Stop once every tracked champion has this number of matches saved on
+	// the current patch, including the ones saved by the previous runs.
+	// Requires trackedChampions, even with allChampions.
+	MatchesPerChampion int `json:"matchesPerChampion"`
+}
+
+// MaxDuration returns the parsed Duration, 0 if unset.
+func (b Budget) MaxDuration() time.Duration {
+	d, _ := time.ParseDuration(b.Duration)
+	return d
+}
+
+// Destinations of the crawled matches.
+const (
+	SinkMySQL  = "mysql"
+	SinkNDJSON = "ndjson"
+	SinkStdout = "stdout"
+)
+
+// Orders in which the players of the frontier are crawled.
+const (
+	// Breadth-first, the players closest to the challenger league first
+	FrontierBFS = "bfs"
+	// The highest ranked players first, one more API call for each player found
+	FrontierRank = "rank"
+	// The players found playing the tracked champions the most first
+	FrontierTracked = "tracked"
+	// The players found in the most recent matches first
+	FrontierRecent = "recent"
+)
+
+type Sink struct {
+	// mysql, ndjson or stdout.
+	Type string `json:"type"`
+	// File the gzipped NDJSON matches are appended to, ndjson only.
+	Path string `json:"path"`
+}
+
+type Config struct {
+	TrackedChampions []TrackedChampion `json:"trackedChampions"`
+	// Save every ranked match whatever the champions played.
+	AllChampions bool `json:"allChampions"`
+	// Platforms crawled concurrently, e.g. euw1 and kr. Each one has its own
+	// rate limit, frontier and budget.
+	Platforms []string `json:"platforms"`
+	Budget    Budget   `json:"budget"`
+	// Every crawled match is saved to each sink.
+	Sinks []Sink `json:"sinks"`
+	// Frontier strategy: bfs, rank, tracked or recent. The priorities of the
+	// players already in the frontier aren't recomputed when it changes.
+	Frontier string `json:"frontier"`
+	// Directory of the raw payloads of the downloaded matches, disabled if
+	// empty.
+	ArchiveDir string `json:"archiveDir"`
+}
+
+// Default returns the configuration used when there is no configuration file.
+func Default() *Config {
+	return &Config{
+		TrackedChampions: []TrackedChampion{
+			{Name: "Samira", Roles: []string{"BOTTOM"}},
+		},
+		Platforms:  []string{"euw1"},
+		Sinks:      []Sink{{Type: SinkMySQL}},
+		Frontier:   FrontierBFS,
+		ArchiveDir: DefaultArchiveDir,
+	}
+}
+
+// Load reads the configuration file pointed by LOL_CONFIG, or DefaultPath.
+// The default configuration is returned if the file doesn't exist.
+func Load() (*Config, error) {
+	path := os.Getenv("LOL_CONFIG")
+	if path == "" {
+		path = DefaultPath
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return nil, err
+	}
+	c := Default()
+	c.TrackedChampions = nil
+	c.Platforms = nil
+	c.Sinks = nil
+	if err = json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("can't parse %s: %w", path, err)
+	}
+	if len(c.Platforms) == 0 {
+		c.Platforms = Default().Platforms
+	}
+	if len(c.Sinks) == 0 {
+		c.Sinks = Default().Sinks
+	}
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UsesSink reports whether a sink of the type is configured.
+func (c *Config) UsesSink(sinkType string) bool {
+	for _, s := range c.Sinks {
+		if s.Type == sinkType {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) Validate() error {
+	if len(c.TrackedChampions) == 0 && !c.AllChampions {
+		return ErrNoTrackedChampion
+	}
+	for _, t := range c.TrackedChampions {
+		for _, r := range t.Roles {
+			if _, ok := gamedata.ParsePosition(r); !ok {
+				return fmt.Errorf("invalid role '%s' for %s", r, t.Name)
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for i, p := range c.Platforms {
+		c.Platforms[i] = strings.ToLower(p)
+		if _, ok := api.RegionalRoute(p); !ok {
+			return fmt.Errorf("unknown platform '%s'", p)
+		}
+		if seen[c.Platforms[i]] {
+			return fmt.Errorf("platform '%s' listed twice", p)
+		}
+		seen[c.Platforms[i]] = true
+	}
+	for _, s := range c.Sinks {
+		switch s.Type {
+		case SinkMySQL, SinkStdout:
+		case SinkNDJSON:
+			if s.Path == "" {
+				return fmt.Errorf("no path given to the %s sink", s.Type)
+			}
+		default:
+			return fmt.Errorf("unknown sink '%s'", s.Type)
+		}
+	}
+	switch c.Frontier {
+	case FrontierBFS, FrontierRank, FrontierTracked, FrontierRecent:
+	default:
+		return fmt.Errorf("unknown frontier strategy '%s'", c.Frontier)
+	}
+	if c.Budget.MatchesPerChampion > 0 && len(c.TrackedChampions) == 0 {
+		return ErrNoChampionBudget
+	}
+	if c.Budget.Duration != "" {
+		if _, err := time.ParseDuration(c.Budget.Duration); err != nil {
+			return fmt.Errorf("invalid budget duration: %w", err)
+		}
+	}
+	return nil
+}
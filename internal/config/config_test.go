@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(c *Config)
+		// Error expected, any error if errAny
+		want   error
+		errAny bool
+	}{
+		{name: "default", edit: func(c *Config) {}},
+		{name: "all champions", edit: func(c *Config) {
+			c.TrackedChampions, c.AllChampions = nil, true
+		}},
+		{name: "no tracked champion", edit: func(c *Config) {
+			c.TrackedChampions = nil
+		}, want: ErrNoTrackedChampion},
+		{name: "invalid role", edit: func(c *Config) {
+			c.TrackedChampions[0].Roles = []string{"ADC"}
+		}, errAny: true},
+		{name: "platform in upper case", edit: func(c *Config) {
+			c.Platforms = []string{"EUW1", "kr"}
+		}},
+		{name: "unknown platform", edit: func(c *Config) {
+			c.Platforms = []string{"moon1"}
+		}, errAny: true},
+		{name: "platform listed twice", edit: func(c *Config) {
+			c.Platforms = []string{"euw1", "EUW1"}
+		}, errAny: true},
+		{name: "ndjson sink without path", edit: func(c *Config) {
+			c.Sinks = []Sink{{Type: SinkNDJSON}}
+		}, errAny: true},
+		{name: "unknown sink", edit: func(c *Config) {
+			c.Sinks = []Sink{{Type: "csv"}}
+		}, errAny: true},
+		{name: "unknown frontier", edit: func(c *Config) {
+			c.Frontier = "dfs"
+		}, errAny: true},
+		{name: "invalid duration", edit: func(c *Config) {
+			c.Budget.Duration = "2 hours"
+		}, errAny: true},
+		{name: "matches per champion", edit: func(c *Config) {
+			c.Budget.MatchesPerChampion = 100
+		}},
+		{name: "matches per champion with all champions", edit: func(c *Config) {
+			c.TrackedChampions, c.AllChampions = nil, true
+			c.Budget.MatchesPerChampion = 100
+		}, want: ErrNoChampionBudget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.edit(c)
+			err := c.Validate()
+			switch {
+			case tt.errAny && err == nil:
+				t.Fatal("Validate() = nil, want an error")
+			case !tt.errAny && !errors.Is(err, tt.want):
+				t.Fatalf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLowersPlatforms(t *testing.T) {
+	c := Default()
+	c.Platforms = []string{"EUW1"}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Platforms[0] != "euw1" {
+		t.Errorf("platform %s, want euw1", c.Platforms[0])
+	}
+}
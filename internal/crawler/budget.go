@@ -0,0 +1,274 @@
+package crawler
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/printer"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// budget tracks the progress of a crawl against the configured stop
+// conditions. Once one of them is reached, the crawl jobs return without
+// starting new work, the pending players staying in the frontier.
+type budget struct {
+	mu        sync.Mutex
+	platform  string
+	limits    config.Budget
+	duration  time.Duration
+	startedAt time.Time
+	calls     func() int
+
+	fetched  int
+	skipped  int
+	saved    int
+	rejected int
+	players  int
+	errors   map[string]int
+	lastErr  error
+	// Matches saved on the current patch, per tracked champion ID
+	perChampion map[int]int
+	reason      string
+}
+
+func newBudget(platform string, limits config.Budget, calls func() int) *budget {
+	return &budget{
+		platform:    platform,
+		limits:      limits,
+		duration:    limits.MaxDuration(),
+		startedAt:   time.Now(),
+		calls:       calls,
+		perChampion: make(map[int]int),
+		errors:      make(map[string]int),
+	}
+}
+
+// exhausted reports whether the crawl has to stop, checking the conditions
+// which don't depend on the saved matches.
+func (b *budget) exhausted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.reason != "":
+	case b.duration > 0 && time.Since(b.startedAt) >= b.duration:
+		b.stopWith("duration reached")
+	case b.limits.MaxAPICalls > 0 && b.calls() >= b.limits.MaxAPICalls:
+		b.stopWith("API calls limit reached")
+	}
+	return b.reason != ""
+}
+
+// deadline returns the end of the duration of the crawl, zero if unlimited.
+func (b *budget) deadline() time.Time {
+	if b.duration <= 0 {
+		return time.Time{}
+	}
+	return b.startedAt.Add(b.duration)
+}
+
+// stopWith must be called with the lock held.
+func (b *budget) stopWith(reason string) {
+	if b.reason != "" {
+		return
+	}
+	b.reason = reason
+	printer.Info("{-F_YELLOW,BOLD}Stopping crawl{-RESET} of %s: %s, finishing the jobs in progress", b.platform, reason)
+}
+
+func (b *budget) countPlayer() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.players++
+	if b.limits.MaxPlayers > 0 && b.players >= b.limits.MaxPlayers {
+		b.stopWith("players limit reached")
+	}
+}
+
+func (b *budget) countFetched() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fetched++
+}
+
+// countSkipped counts a match not fetched because it was already processed.
+func (b *budget) countSkipped() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.skipped++
+}
+
+func (b *budget) countError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errors[ErrorKind(err)]++
+	b.lastErr = err
+}
+
+func (b *budget) countRejected() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rejected++
+}
+
+// countSaved counts the saved match, the champions being counted only if it
+// was played on the current patch.
+func (b *budget) countSaved(m *gamedata.MatchData, f *Filter, currentPatch string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.saved++
+	if m.Patch() == currentPatch {
+		for i := range m.Info.Participants {
+			if p := &m.Info.Participants[i]; f.IsTracked(p) {
+				b.perChampion[p.ChampionId]++
+			}
+		}
+	}
+	if b.limits.MaxMatches > 0 && b.saved >= b.limits.MaxMatches {
+		b.stopWith("matches limit reached")
+	}
+	b.checkPerChampion(f)
+}
+
+// seed adds the matches of the current patch saved by the previous runs, per
+// tracked champion.
+func (b *budget) seed(perChampion map[int]int, f *Filter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, n := range perChampion {
+		b.perChampion[id] += n
+	}
+	b.checkPerChampion(f)
+}
+
+// checkPerChampion stops the crawl once every tracked champion has enough
+// matches on the current patch. Must be called with the lock held.
+func (b *budget) checkPerChampion(f *Filter) {
+	if b.limits.MatchesPerChampion <= 0 || len(f.tracked) == 0 {
+		return
+	}
+	for id := range f.tracked {
+		if b.perChampion[id] < b.limits.MatchesPerChampion {
+			return
+		}
+	}
+	b.stopWith("enough matches per tracked champion")
+}
+
+// completion returns the progress toward the closest stop condition, from 0
+// to 1, and false if there is no stop condition. Must be called with the lock
+// held.
+func (b *budget) completion(f *Filter) (float64, bool) {
+	progress := make([]float64, 0, 5)
+	ratio := func(n, limit int) {
+		if limit > 0 {
+			progress = append(progress, float64(n)/float64(limit))
+		}
+	}
+	ratio(b.saved, b.limits.MaxMatches)
+	ratio(b.calls(), b.limits.MaxAPICalls)
+	ratio(b.players, b.limits.MaxPlayers)
+	if b.duration > 0 {
+		progress = append(progress, float64(time.Since(b.startedAt))/float64(b.duration))
+	}
+	if b.limits.MatchesPerChampion > 0 && len(f.tracked) > 0 {
+		least := -1
+		for id := range f.tracked {
+			if least < 0 || b.perChampion[id] < least {
+				least = b.perChampion[id]
+			}
+		}
+		ratio(least, b.limits.MatchesPerChampion)
+	}
+	if len(progress) == 0 {
+		return 0, false
+	}
+	best := 0.0
+	for _, p := range progress {
+		if p > best {
+			best = p
+		}
+	}
+	if best > 1 {
+		best = 1
+	}
+	return best, true
+}
+
+// printSummary prints the progress of the crawl.
+func (b *budget) printSummary(f *Filter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	reason := b.reason
+	if reason == "" {
+		reason = "frontier exhausted"
+	}
+	printer.Info("{-BOLD}Crawl summary of %s{-RESET} (%s)", b.platform, reason)
+	printer.Info("  Duration: {-F_MAGENTA,BOLD}%s", time.Since(b.startedAt).Round(time.Second))
+	printer.Info("  API calls: {-F_MAGENTA,BOLD}%d", b.calls())
+	printer.Info("  Players visited: {-F_MAGENTA,BOLD}%d", b.players)
+	printer.Info("  Matches saved: {-F_MAGENTA,BOLD}%d{-RESET}, rejected: {-F_MAGENTA,BOLD}%d", b.saved, b.rejected)
+	ids := make([]int, 0, len(f.tracked))
+	for id := range f.tracked {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return f.tracked[ids[i]].name < f.tracked[ids[j]].name
+	})
+	for _, id := range ids {
+		printer.Info("  %s on the current patch: {-F_MAGENTA,BOLD}%d", f.tracked[id].name, b.perChampion[id])
+	}
+}
+
+// Progress is a snapshot of the crawl of a platform.
+type Progress struct {
+	Platform string
+	Elapsed  time.Duration
+	Players  int
+	Fetched  int
+	Skipped  int
+	Saved    int
+	Rejected int
+	Calls    int
+	Waited   time.Duration
+	// Errors count per kind, see ErrorKind
+	Errors    map[string]int
+	LastError error
+	// From 0 to 1, toward the closest stop condition of the budget
+	Completion float64
+	HasBudget  bool
+	StopReason string
+}
+
+// ETA returns the estimated time left before the budget is reached, 0 if
+// unknown.
+func (p Progress) ETA() time.Duration {
+	if !p.HasBudget || p.Completion <= 0 || p.Completion >= 1 {
+		return 0
+	}
+	return time.Duration(float64(p.Elapsed) * (1 - p.Completion) / p.Completion)
+}
+
+func (b *budget) progress(f *Filter) Progress {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p := Progress{
+		Platform:   b.platform,
+		Elapsed:    time.Since(b.startedAt),
+		Players:    b.players,
+		Fetched:    b.fetched,
+		Skipped:    b.skipped,
+		Saved:      b.saved,
+		Rejected:   b.rejected,
+		Calls:      b.calls(),
+		Errors:     make(map[string]int, len(b.errors)),
+		LastError:  b.lastErr,
+		StopReason: b.reason,
+	}
+	for k, v := range b.errors {
+		p.Errors[k] = v
+	}
+	p.Completion, p.HasBudget = b.completion(f)
+	return p
+}
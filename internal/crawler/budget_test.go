@@ -0,0 +1,104 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+const (
+	samiraID = 360
+	jinxID   = 222
+)
+
+// trackingFilter tracks Samira bottom and Jinx in any position.
+func trackingFilter() *Filter {
+	return &Filter{
+		tracked: map[int]*trackedChampion{
+			samiraID: {name: "Samira", positions: map[gamedata.Position]bool{gamedata.PositionBottom: true}},
+			jinxID:   {name: "Jinx", positions: map[gamedata.Position]bool{}},
+		},
+		positions: map[gamedata.Position]bool{},
+	}
+}
+
+func patchMatch(version string, champions ...int) *gamedata.MatchData {
+	m := &gamedata.MatchData{}
+	m.Info.GameVersion = version
+	for _, id := range champions {
+		m.Info.Participants = append(m.Info.Participants, gamedata.Participant{ChampionId: id, TeamPosition: string(gamedata.PositionBottom)})
+	}
+	return m
+}
+
+func TestTrackedMatches(t *testing.T) {
+	counts := []database.PatchMatchCount{
+		{ChampionID: samiraID, Position: "BOTTOM", Matches: 4},
+		{ChampionID: samiraID, Position: "MIDDLE", Matches: 3},
+		{ChampionID: jinxID, Position: "BOTTOM", Matches: 2},
+		{ChampionID: jinxID, Position: "", Matches: 1},
+		{ChampionID: 1, Position: "BOTTOM", Matches: 9},
+	}
+	got := trackingFilter().trackedMatches(counts)
+	if got[samiraID] != 4 || got[jinxID] != 3 || len(got) != 2 {
+		t.Errorf("trackedMatches() = %v, want Samira 4 and Jinx 3", got)
+	}
+}
+
+func TestBudgetMatchesPerChampion(t *testing.T) {
+	tests := []struct {
+		name    string
+		seeded  map[int]int
+		matches []*gamedata.MatchData
+		stopped bool
+	}{
+		{name: "nothing saved"},
+		{
+			name:    "seeded by the previous runs",
+			seeded:  map[int]int{samiraID: 2, jinxID: 2},
+			stopped: true,
+		},
+		{
+			name:    "seeded and saved",
+			seeded:  map[int]int{samiraID: 2, jinxID: 1},
+			matches: []*gamedata.MatchData{patchMatch("14.3.1", jinxID)},
+			stopped: true,
+		},
+		{
+			name:    "one champion missing",
+			seeded:  map[int]int{samiraID: 5},
+			matches: []*gamedata.MatchData{patchMatch("14.3.1", jinxID)},
+		},
+		{
+			name:    "saved on the previous patch",
+			seeded:  map[int]int{samiraID: 2, jinxID: 1},
+			matches: []*gamedata.MatchData{patchMatch("14.2.7", jinxID)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := trackingFilter()
+			b := newBudget("euw1", config.Budget{MatchesPerChampion: 2}, func() int { return 0 })
+			b.seed(tt.seeded, f)
+			for _, m := range tt.matches {
+				b.countSaved(m, f, "14.3")
+			}
+			if got := b.exhausted(); got != tt.stopped {
+				t.Errorf("exhausted() = %v, want %v", got, tt.stopped)
+			}
+		})
+	}
+}
+
+func TestBudgetDeadline(t *testing.T) {
+	if d := newBudget("euw1", config.Budget{}, func() int { return 0 }).deadline(); !d.IsZero() {
+		t.Errorf("deadline() = %v without duration, want none", d)
+	}
+	b := newBudget("euw1", config.Budget{Duration: "30m"}, func() int { return 0 })
+	if d := b.deadline(); !d.Equal(b.startedAt.Add(30 * time.Minute)) {
+		t.Errorf("deadline() = %v, want 30 minutes after %v", d, b.startedAt)
+	}
+}
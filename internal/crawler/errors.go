@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"LoLItemRecommender/internal/riotapi/api"
+)
+
+var ErrSave = errors.New("can't save match")
+
+// Kinds of crawl errors.
+const (
+	KindRateLimited = "rate limited"
+	KindNotFound    = "not found"
+	KindForbidden   = "forbidden"
+	KindServer      = "server error"
+	KindHTTP        = "http error"
+	KindNetwork     = "network"
+	KindDecode      = "decode"
+	KindSave        = "save"
+	KindOther       = "other"
+)
+
+// ErrorKind classifies a crawl error.
+func ErrorKind(err error) string {
+	var (
+		status *api.StatusError
+		netErr net.Error
+		syntax *json.SyntaxError
+		types  *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &status):
+		switch {
+		case status.Code == http.StatusTooManyRequests:
+			return KindRateLimited
+		case status.Code == http.StatusNotFound:
+			return KindNotFound
+		case status.Code == http.StatusUnauthorized || status.Code == http.StatusForbidden:
+			return KindForbidden
+		case status.Code >= 500:
+			return KindServer
+		}
+		return KindHTTP
+	case errors.As(err, &netErr):
+		return KindNetwork
+	case errors.As(err, &syntax), errors.As(err, &types):
+		return KindDecode
+	case errors.Is(err, ErrSave):
+		return KindSave
+	}
+	return KindOther
+}
+
+// Transient reports whether the error may go away on a retry: a rate
+// limit, a server or a network error.
+func Transient(err error) bool {
+	switch ErrorKind(err) {
+	case KindRateLimited, KindServer, KindNetwork:
+		return true
+	}
+	return false
+}
+
+func saveError(err error) error {
+	return fmt.Errorf("%w: %w", ErrSave, err)
+}
@@ -0,0 +1,120 @@
+package crawler
+
+import (
+	"fmt"
+	"strconv"
+
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+type trackedChampion struct {
+	name string
+	// Any position if empty
+	positions map[gamedata.Position]bool
+}
+
+func (t *trackedChampion) playsIn(position gamedata.Position) bool {
+	return len(t.positions) == 0 || t.positions[position]
+}
+
+// Filter decides which matches are saved and which participants are crawled,
+// from the tracked champions of the configuration.
+type Filter struct {
+	tracked map[int]*trackedChampion
+	// Union of the tracked positions, every position if empty
+	positions map[gamedata.Position]bool
+	all       bool
+}
+
+func NewFilter(cfg *config.Config, sd *gamedata.StaticData) (*Filter, error) {
+	f := &Filter{
+		tracked:   make(map[int]*trackedChampion),
+		positions: make(map[gamedata.Position]bool),
+		all:       cfg.AllChampions,
+	}
+	anyPosition := false
+	for _, t := range cfg.TrackedChampions {
+		c := sd.GetChampionStats(t.Name)
+		if c == nil {
+			return nil, fmt.Errorf("unknown tracked champion '%s'", t.Name)
+		}
+		id, err := strconv.Atoi(c.Key)
+		if err != nil {
+			return nil, err
+		}
+		tc := &trackedChampion{name: c.Name, positions: make(map[gamedata.Position]bool)}
+		for _, r := range t.Roles {
+			pos, _ := gamedata.ParsePosition(r)
+			tc.positions[pos] = true
+			f.positions[pos] = true
+		}
+		anyPosition = anyPosition || len(t.Roles) == 0
+		f.tracked[id] = tc
+	}
+	if anyPosition || f.all {
+		f.positions = make(map[gamedata.Position]bool)
+	}
+	return f, nil
+}
+
+// TrackedNames returns the names of the tracked champions.
+func (f *Filter) TrackedNames() []string {
+	names := make([]string, 0, len(f.tracked))
+	for _, t := range f.tracked {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// IsTracked reports whether the participant plays a tracked champion in one
+// of its tracked positions.
+func (f *Filter) IsTracked(p *gamedata.Participant) bool {
+	t, ok := f.tracked[p.ChampionId]
+	return ok && t.playsIn(p.Position())
+}
+
+// trackedMatches returns the number of matches per tracked champion, from
+// the matches counted per champion and position.
+func (f *Filter) trackedMatches(counts []database.PatchMatchCount) map[int]int {
+	matches := make(map[int]int)
+	for _, c := range counts {
+		if t, ok := f.tracked[c.ChampionID]; ok && t.playsIn(gamedata.Position(c.Position)) {
+			matches[c.ChampionID] += c.Matches
+		}
+	}
+	return matches
+}
+
+// KeepMatch reports whether the match has to be saved.
+func (f *Filter) KeepMatch(m *gamedata.MatchData) bool {
+	return f.RejectReason(m) == ""
+}
+
+// RejectReason returns why the match isn't saved, empty if it is.
+func (f *Filter) RejectReason(m *gamedata.MatchData) string {
+	if !m.Info.QueueId.IsRanked() {
+		return fmt.Sprintf("queue %s", m.Info.QueueId)
+	}
+	if f.all {
+		return ""
+	}
+	reason := "no tracked champion"
+	for i := range m.Info.Participants {
+		p := &m.Info.Participants[i]
+		if f.IsTracked(p) {
+			return ""
+		}
+		if _, ok := f.tracked[p.ChampionId]; ok {
+			reason = "tracked champion in an untracked position"
+		}
+	}
+	return reason
+}
+
+// Follow reports whether the participant is worth crawling, the players in
+// the tracked positions being the most likely to play the tracked champions.
+func (f *Filter) Follow(p *gamedata.Participant) bool {
+	return len(f.positions) == 0 || f.positions[p.Position()]
+}
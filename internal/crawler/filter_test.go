@@ -0,0 +1,50 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+func rankedMatch(participants ...gamedata.Participant) *gamedata.MatchData {
+	m := &gamedata.MatchData{}
+	m.Info.QueueId = gamedata.QueueRankedSolo
+	m.Info.Participants = participants
+	return m
+}
+
+func playing(championID int, pos gamedata.Position) gamedata.Participant {
+	return gamedata.Participant{ChampionId: championID, TeamPosition: string(pos)}
+}
+
+func TestRejectReason(t *testing.T) {
+	aram := rankedMatch(playing(samiraID, gamedata.PositionBottom))
+	aram.Info.QueueId = gamedata.QueueARAM
+	tests := []struct {
+		name  string
+		all   bool
+		match *gamedata.MatchData
+		want  string
+	}{
+		{"tracked champion in its position", false, rankedMatch(playing(1, gamedata.PositionTop), playing(samiraID, gamedata.PositionBottom)), ""},
+		{"tracked in any position", false, rankedMatch(playing(jinxID, gamedata.PositionMiddle)), ""},
+		{"untracked position", false, rankedMatch(playing(samiraID, gamedata.PositionMiddle), playing(1, gamedata.PositionBottom)), "tracked champion in an untracked position"},
+		{"no tracked champion", false, rankedMatch(playing(1, gamedata.PositionBottom)), "no tracked champion"},
+		{"all champions", true, rankedMatch(playing(1, gamedata.PositionBottom)), ""},
+		{"unranked queue", true, aram, "queue "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := trackingFilter()
+			f.all = tt.all
+			got := f.RejectReason(tt.match)
+			if got != tt.want && (tt.want == "" || !strings.HasPrefix(got, tt.want)) {
+				t.Errorf("RejectReason() = %q, want %q", got, tt.want)
+			}
+			if keep := f.KeepMatch(tt.match); keep != (got == "") {
+				t.Errorf("KeepMatch() = %v with reason %q", keep, got)
+			}
+		})
+	}
+}
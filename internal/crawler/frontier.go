@@ -0,0 +1,173 @@
+package crawler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// ChallengerPriority is the crawl priority of the players seeding an empty
+// frontier with the bfs strategy, each player found in their matches getting
+// one less.
+const ChallengerPriority = 100
+
+// Candidate is a player found in a crawled match, waiting to be scored.
+type Candidate struct {
+	// Player whose match list contained the match
+	From        *gamedata.Player
+	Participant *gamedata.Participant
+	Match       *gamedata.MatchData
+	// Crawled matches in which the player played a tracked champion, by this
+	// run and the previous ones
+	TrackedGames int
+	// Solo queue rank of the player, only retrieved for the rank strategy, nil
+	// if unranked
+	Rank *gamedata.LeagueEntry
+}
+
+// Strategy scores the players added to the frontier, the highest score being
+// crawled first. Players with the same score are crawled in the order they
+// were found.
+type Strategy interface {
+	// Seed scores a player of the challenger league seeding the frontier.
+	Seed(p *gamedata.Player) int
+	Score(c *Candidate) int
+}
+
+// NewStrategy returns the frontier strategy named in the configuration.
+func NewStrategy(name string) (Strategy, error) {
+	switch name {
+	case config.FrontierBFS:
+		return bfsStrategy{}, nil
+	case config.FrontierRank:
+		return rankStrategy{}, nil
+	case config.FrontierTracked:
+		return trackedStrategy{}, nil
+	case config.FrontierRecent:
+		return recentStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown frontier strategy '%s'", name)
+}
+
+type bfsStrategy struct{}
+
+func (bfsStrategy) Seed(*gamedata.Player) int {
+	return ChallengerPriority
+}
+
+func (bfsStrategy) Score(c *Candidate) int {
+	return c.From.Priority - 1
+}
+
+// rankStrategy crawls the highest ranked players first, scored by their solo
+// queue rank. The rank of an unranked player is estimated from the one who
+// found it, matchmaking pairing players of similar ranks, losing a tenth of
+// its score.
+type rankStrategy struct{}
+
+func (rankStrategy) Seed(p *gamedata.Player) int {
+	return rankScore(&gamedata.LeagueEntry{Tier: gamedata.Challenger, Rank: gamedata.TierOne, LeaguePoints: p.LeaguePoints})
+}
+
+func (rankStrategy) Score(c *Candidate) int {
+	if c.Rank != nil {
+		if s := rankScore(c.Rank); s > 0 {
+			return s
+		}
+	}
+	return c.From.Priority * 9 / 10
+}
+
+var (
+	// Tiers from the lowest, the master ones sharing their league points
+	tierScores = map[string]int{
+		gamedata.Iron:        1,
+		gamedata.Bronze:      2,
+		gamedata.Silver:      3,
+		gamedata.Gold:        4,
+		gamedata.Platinum:    5,
+		gamedata.Emerald:     6,
+		gamedata.Diamond:     7,
+		gamedata.Master:      8,
+		gamedata.Grandmaster: 8,
+		gamedata.Challenger:  8,
+	}
+	divisionScores = map[string]int{
+		gamedata.TierFour:  0,
+		gamedata.TierThree: 1,
+		gamedata.TierTwo:   2,
+		gamedata.TierOne:   3,
+	}
+)
+
+// rankScore returns the score of a rank, 400 points a tier and 100 a
+// division plus the league points, 0 for an unknown tier.
+func rankScore(e *gamedata.LeagueEntry) int {
+	tier, ok := tierScores[e.Tier]
+	if !ok {
+		return 0
+	}
+	if tier == tierScores[gamedata.Master] {
+		return tier*400 + e.LeaguePoints
+	}
+	return tier*400 + divisionScores[e.Rank]*100 + e.LeaguePoints
+}
+
+// trackedStrategy crawls the players found playing the tracked champions
+// first, breadth-first for the others.
+type trackedStrategy struct{}
+
+func (trackedStrategy) Seed(*gamedata.Player) int {
+	return 0
+}
+
+func (trackedStrategy) Score(c *Candidate) int {
+	return c.TrackedGames
+}
+
+// recentStrategy crawls the players of the latest matches first, scored by
+// the start of the match in epoch minutes.
+type recentStrategy struct{}
+
+func (recentStrategy) Seed(*gamedata.Player) int {
+	return int(time.Now().Unix() / 60)
+}
+
+func (recentStrategy) Score(c *Candidate) int {
+	return int(c.Match.Info.GameCreation / time.Minute.Milliseconds())
+}
+
+// trackedCounts counts the crawled matches in which each player played a
+// tracked champion, seeded with the counts of the previous runs.
+type trackedCounts struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newTrackedCounts() *trackedCounts {
+	return &trackedCounts{counts: make(map[string]int)}
+}
+
+func (t *trackedCounts) seed(players []database.TrackedPlayer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range players {
+		t.counts[p.SummonerID] = p.TrackedGames
+	}
+}
+
+func (t *trackedCounts) add(summonerID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[summonerID]++
+}
+
+func (t *trackedCounts) get(summonerID string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[summonerID]
+}
@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"testing"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+func TestTrackedStrategyAcrossRuns(t *testing.T) {
+	state := NewMemoryState()
+	for _, id := range []string{"a", "a", "b"} {
+		if err := state.CountTrackedGame("euw1", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := state.CountTrackedGame("kr", "a"); err != nil {
+		t.Fatal(err)
+	}
+	players, err := state.GetTrackedPlayers("euw1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracked := newTrackedCounts()
+	tracked.seed(players)
+	tracked.add("b")
+	tracked.add("c")
+
+	s := trackedStrategy{}
+	for id, want := range map[string]int{"a": 2, "b": 2, "c": 1, "d": 0} {
+		if got := s.Score(&Candidate{TrackedGames: tracked.get(id)}); got != want {
+			t.Errorf("Score(%s) = %d, want %d", id, got, want)
+		}
+	}
+}
+
+func TestRankStrategy(t *testing.T) {
+	from := &gamedata.Player{Priority: 1000}
+	tests := []struct {
+		name string
+		rank *gamedata.LeagueEntry
+		want int
+	}{
+		{name: "unranked", want: 900},
+		{name: "unknown tier", rank: &gamedata.LeagueEntry{Tier: "WOOD"}, want: 900},
+		{name: "iron IV", rank: &gamedata.LeagueEntry{Tier: gamedata.Iron, Rank: gamedata.TierFour, LeaguePoints: 10}, want: 410},
+		{name: "gold II", rank: &gamedata.LeagueEntry{Tier: gamedata.Gold, Rank: gamedata.TierTwo, LeaguePoints: 50}, want: 1850},
+		{name: "diamond I", rank: &gamedata.LeagueEntry{Tier: gamedata.Diamond, Rank: gamedata.TierOne, LeaguePoints: 99}, want: 3199},
+		{name: "master", rank: &gamedata.LeagueEntry{Tier: gamedata.Master, Rank: gamedata.TierOne, LeaguePoints: 120}, want: 3320},
+		{name: "grandmaster", rank: &gamedata.LeagueEntry{Tier: gamedata.Grandmaster, Rank: gamedata.TierOne, LeaguePoints: 600}, want: 3800},
+	}
+	s := rankStrategy{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Score(&Candidate{From: from, Rank: tt.rank}); got != tt.want {
+				t.Errorf("Score() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if got := s.Seed(&gamedata.Player{LeaguePoints: 1500}); got != 4700 {
+		t.Errorf("Seed() = %d for a challenger of 1500 LP, want 4700", got)
+	}
+}
@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"LoLItemRecommender/internal/queue"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// Kinds of the crawl jobs.
+const (
+	JobCrawlPlayer   = "crawl-player"
+	JobRefreshPlayer = "refresh-player"
+)
+
+// playerRetry retries the crawl of a player failing on a transient error.
+var playerRetry = queue.RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     10 * time.Second,
+	Retryable:   Transient,
+}
+
+// playerPayload is the payload of the crawl jobs, with the fields of the
+// player not sent by Riot.
+type playerPayload struct {
+	Player        gamedata.Player `json:"player"`
+	Priority      int             `json:"priority"`
+	LastMatchTime int64           `json:"lastMatchTime"`
+}
+
+// PlayerJob returns the job crawling the player, JobCrawlPlayer or
+// JobRefreshPlayer, skipped once the duration of the crawl is reached, the
+// player staying in the frontier for the next run.
+func (gd *GameData) PlayerJob(kind string, player *gamedata.Player) (queue.Job, error) {
+	j, err := queue.NewJob(kind, player.Priority, playerPayload{
+		Player:        *player,
+		Priority:      player.Priority,
+		LastMatchTime: player.LastMatchTime,
+	})
+	if err != nil {
+		return queue.Job{}, err
+	}
+	j.Deadline = gd.budget.deadline()
+	return j, nil
+}
+
+func decodePlayer(j *queue.Job) (*gamedata.Player, error) {
+	var p playerPayload
+	if err := json.Unmarshal(j.Payload, &p); err != nil {
+		return nil, err
+	}
+	p.Player.Priority = p.Priority
+	p.Player.LastMatchTime = p.LastMatchTime
+	return &p.Player, nil
+}
+
+// RegisterJobs sets the handlers and the retry policies of the crawl jobs of
+// the pool.
+func (gd *GameData) RegisterJobs(pool *queue.Pool) {
+	pool.Register(JobCrawlPlayer, func(ctx context.Context, j *queue.Job) error {
+		player, err := decodePlayer(j)
+		if err != nil {
+			return err
+		}
+		return gd.CrawlPlayerData(ctx, player, pool)
+	})
+	pool.Register(JobRefreshPlayer, func(ctx context.Context, j *queue.Job) error {
+		player, err := decodePlayer(j)
+		if err != nil {
+			return err
+		}
+		return gd.RefreshPlayerData(ctx, player)
+	})
+	pool.SetRetryPolicy(JobCrawlPlayer, playerRetry)
+	pool.SetRetryPolicy(JobRefreshPlayer, playerRetry)
+}
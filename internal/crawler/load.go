@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	"sync"
+	"time"
+
+	"LoLItemRecommender/internal/queue"
+)
+
+// writeStats measures the latency of the matches saved to the sink.
+type writeStats struct {
+	mu    sync.Mutex
+	total time.Duration
+	n     int
+}
+
+func (w *writeStats) add(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.total += d
+	w.n++
+}
+
+// reset returns the mean latency since the previous reset.
+func (w *writeStats) reset() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var mean time.Duration
+	if w.n > 0 {
+		mean = w.total / time.Duration(w.n)
+	}
+	w.total, w.n = 0, 0
+	return mean
+}
+
+// SampleLoad returns the load of the crawl since the previous call, to scale
+// its pool with queue.Pool.AutoScale.
+func (gd *GameData) SampleLoad() queue.Load {
+	gd.loadMu.Lock()
+	defer gd.loadMu.Unlock()
+	waited := gd.client.Waited()
+	l := queue.Load{
+		RateLimitWait: waited - gd.lastWaited,
+		WriteLatency:  gd.writes.reset(),
+	}
+	gd.lastWaited = waited
+	return l
+}
@@ -1,61 +1,233 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/url"
 	"os"
-	"strconv"
 	"sync"
+	"time"
 
-	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/archive"
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/positions"
 	"LoLItemRecommender/internal/printer"
 	"LoLItemRecommender/internal/queue"
 	"LoLItemRecommender/internal/riotapi/api"
 	"LoLItemRecommender/internal/riotapi/gamedata"
 )
 
+// GameData crawls the matches of one platform.
 type GameData struct {
-	em             *api.EndpointsManager
-	client         *api.Client
-	staticData     *gamedata.StaticData
-	playersCrawled *sync.Map
-	db             *database.DB
-	lookingChampID int
+	platform string
+	em       *api.EndpointsManager
+	client   *api.Meter
+	static   *gamedata.Watcher
+	visited  *sync.Map
+	matches  *matchIndex
+	state    StateStore
+	sink     MatchSink
+	archive  *archive.Store
+	filter   *Filter
+	budget   *budget
+	strategy Strategy
+	tracked  *trackedCounts
+	// Solo queue ranks by summoner ID, retrieved for the rank strategy
+	ranks  *sync.Map
+	writes writeStats
+	// Rate limit wait at the previous load sample
+	loadMu     sync.Mutex
+	lastWaited time.Duration
 	//playersData map[string]*gamedata.Player
 }
 
-func NewGameData(region string, db *database.DB) (*GameData, error) {
+var (
+	clientsMu sync.Mutex
+	// Clients by regional route, the platforms of a route sharing its rate
+	// limit
+	clients = make(map[string]*api.Client)
+)
+
+// routeClient returns the client shared by the platforms of the regional
+// route of the platform, europe being the default like for the endpoints.
+func routeClient(platform string) *api.Client {
+	route, ok := api.RegionalRoute(platform)
+	if !ok {
+		route = "europe"
+	}
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	c, ok := clients[route]
+	if !ok {
+		c = api.NewClient()
+		clients[route] = c
+	}
+	return c
+}
+
+// NewGameData returns the crawler of the platform, e.g. euw1, saving its
+// progress to state and the kept matches to sink. The static data are shared
+// by the crawlers of every platform.
+func NewGameData(platform string, state StateStore, sink MatchSink, cfg *config.Config, static *gamedata.Watcher) (*GameData, error) {
 	gd := &GameData{
-		em: api.NewEndpointsManager(os.Getenv("RIOT_API_KEY"), region),
+		platform: platform,
+		em:       api.NewEndpointsManager(os.Getenv("RIOT_API_KEY"), platform),
 		//playersData: make(map[string]*gamedata.Player),
-		client:         api.NewClient(),
-		playersCrawled: &sync.Map{},
-		db:             db,
+		client:  routeClient(platform).Meter(),
+		static:  static,
+		visited: &sync.Map{},
+		matches: newMatchIndex(nil),
+		state:   state,
+		sink:    sink,
+		tracked: newTrackedCounts(),
 	}
-	gd.staticData = gamedata.NewStaticData(gd.em, gd.client)
-	if err := gd.staticData.RetrieveAPIVersion(); err != nil {
+	var err error
+	if gd.filter, err = NewFilter(cfg, static.Current()); err != nil {
 		return nil, err
 	}
-	if err := gd.staticData.RetrieveChampionsStats(); err != nil {
+	if gd.strategy, err = NewStrategy(cfg.Frontier); err != nil {
 		return nil, err
 	}
-	if err := gd.staticData.RetrieveItems(); err != nil {
-		return nil, err
+	if _, ok := gd.strategy.(rankStrategy); ok {
+		gd.ranks = &sync.Map{}
+	}
+	gd.budget = newBudget(platform, cfg.Budget, gd.client.Calls)
+	if cfg.AllChampions {
+		gd.info("Saving every ranked match")
+	} else {
+		gd.info("Tracking {-F_YELLOW}%v", gd.filter.TrackedNames())
 	}
-
-	var lookingForChampID, _ = strconv.Atoi(gd.staticData.ChampionsStats["Samira"].Key)
-	gd.lookingChampID = lookingForChampID
 	return gd, nil
 }
 
-func (gd *GameData) FlagPlayers(names []string) {
-	for _, n := range names {
-		gd.playersCrawled.Store(n, true)
+// Platform returns the crawled platform.
+func (gd *GameData) Platform() string {
+	return gd.platform
+}
+
+// info logs the message prefixed by the platform.
+func (gd *GameData) info(format string, a ...any) {
+	printer.Info("{-F_CYAN}[%s]{-RESET} "+format, append([]any{gd.platform}, a...)...)
+}
+
+// StaticData returns the static data of the latest known patch.
+func (gd *GameData) StaticData() *gamedata.StaticData {
+	return gd.static.Current()
+}
+
+// WatchPatches reloads the static data when a new patch is published and
+// records the patch boundary, until the context is done.
+func WatchPatches(ctx context.Context, static *gamedata.Watcher, state StateStore) {
+	go static.Run(ctx)
+	for e := range static.Events() {
+		printer.Info("{-F_MAGENTA,BOLD}Patch changed{-RESET} from %s to %s", e.Previous, e.Current)
+		if err := state.SavePatchBoundary(e.Previous, e.Current); err != nil {
+			printer.Error("Unable to save the patch boundary: %v", err)
+		}
 	}
 }
 
-func (gd *GameData) RetrieveAdditionalPlayerData(player *gamedata.Player) error {
-	b, err := gd.client.Get(gd.em.GetSummonerByName(url.PathEscape(player.SummonerName)))
+// Progress returns a snapshot of the progress of the crawl.
+func (gd *GameData) Progress() Progress {
+	p := gd.budget.progress(gd.filter)
+	p.Waited = gd.client.Waited()
+	return p
+}
+
+// RecordError counts an error returned by a crawl job.
+func (gd *GameData) RecordError(err error) {
+	gd.budget.countError(err)
+}
+
+// PrintSummary prints the progress of the crawl against its budget.
+func (gd *GameData) PrintSummary() {
+	gd.budget.printSummary(gd.filter)
+}
+
+// refreshOverlap is subtracted from the time a match list is retrieved, for
+// the matches being played at that time to be in the next refresh.
+const refreshOverlap = time.Hour
+
+// loadState loads the visited players, the processed matches and the tracked
+// games of the players, and returns the number of visited players.
+func (gd *GameData) loadState() (int, error) {
+	visited, err := gd.state.GetVisited(gd.platform)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range visited {
+		gd.visited.Store(id, true)
+	}
+	processed, err := gd.state.GetProcessedMatches(gd.platform)
+	if err != nil {
+		return 0, err
+	}
+	gd.matches = newMatchIndex(processed)
+	saved, rejected := gd.matches.counts()
+	gd.info("Skipping {-F_MAGENTA,BOLD}%d {-RESET}saved and {-F_MAGENTA,BOLD}%d {-RESET}rejected matches", saved, rejected)
+	patch := gamedata.Patch(gd.StaticData().APIVersion)
+	counts, err := gd.state.CountPatchMatches(gd.platform, patch)
+	if err != nil {
+		return 0, err
+	}
+	gd.budget.seed(gd.filter.trackedMatches(counts), gd.filter)
+	tracked, err := gd.state.GetTrackedPlayers(gd.platform)
+	if err != nil {
+		return 0, err
+	}
+	gd.tracked.seed(tracked)
+	return len(visited), nil
+}
+
+// RefreshPlayers loads the crawl state and returns the visited players to
+// refresh, the ones playing the tracked champions the most first.
+func (gd *GameData) RefreshPlayers() ([]*gamedata.Player, error) {
+	if _, err := gd.loadState(); err != nil {
+		return nil, err
+	}
+	players, err := gd.state.GetRefreshPlayers(gd.platform)
+	if err != nil {
+		return nil, err
+	}
+	// Refreshed first by the pool
+	for _, p := range players {
+		p.Priority = p.TrackedGames
+	}
+	gd.info("Refreshing {-F_MAGENTA,BOLD}%d {-RESET}players", len(players))
+	return players, nil
+}
+
+// ResumeCrawl loads the crawl state and returns the frontier saved by the
+// previous run, seeded with the challenger players if it is empty.
+func (gd *GameData) ResumeCrawl() ([]*gamedata.Player, error) {
+	visited, err := gd.loadState()
+	if err != nil {
+		return nil, err
+	}
+	frontier, err := gd.state.GetFrontier(gd.platform)
+	if err != nil {
+		return nil, err
+	}
+	if len(frontier) > 0 {
+		gd.info("Resuming crawl with {-F_MAGENTA,BOLD}%d {-RESET}pending and {-F_MAGENTA,BOLD}%d {-RESET}visited players", len(frontier), visited)
+		return frontier, nil
+	}
+	players, err := gd.InitWithChallengerPlayers()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range players {
+		p.Priority = gd.strategy.Seed(p)
+		if err := gd.state.AddToFrontier(gd.platform, p, p.Priority); err != nil {
+			return nil, err
+		}
+	}
+	return players, nil
+}
+
+func (gd *GameData) RetrieveAdditionalPlayerData(ctx context.Context, player *gamedata.Player) error {
+	b, err := gd.client.GetContext(ctx, gd.em.GetSummonerByName(url.PathEscape(player.SummonerName)))
 	if err != nil {
 		return err
 	}
@@ -65,8 +237,34 @@ func (gd *GameData) RetrieveAdditionalPlayerData(player *gamedata.Player) error
 	return nil
 }
 
-func (gd *GameData) RetrievePlayerGamesId(player *gamedata.Player) ([]string, error) {
-	b, err := gd.client.Get(gd.em.GetMatchListURL(player.Puuid))
+// RetrievePlayerGamesId returns the latest matches of the player, or all the
+// ones played since its LastMatchTime if set, listed page by page.
+func (gd *GameData) RetrievePlayerGamesId(ctx context.Context, player *gamedata.Player) ([]string, error) {
+	if player.LastMatchTime == 0 {
+		matchIDs, err := gd.retrieveMatchList(ctx, gd.em.GetMatchListURL(player.Puuid))
+		if err != nil {
+			return nil, err
+		}
+		gd.info("Found {-F_MAGENTA,BOLD}%d {-RESET}games for {-F_YELLOW}%s", len(matchIDs), player.SummonerName)
+		return matchIDs, nil
+	}
+	var matchIDs []string
+	for {
+		page, err := gd.retrieveMatchList(ctx, gd.em.GetMatchListSinceURL(player.Puuid, player.LastMatchTime/1000, len(matchIDs)))
+		if err != nil {
+			return nil, err
+		}
+		matchIDs = append(matchIDs, page...)
+		if len(page) < api.MatchListPageSize {
+			break
+		}
+	}
+	gd.info("Found {-F_MAGENTA,BOLD}%d {-RESET}games for {-F_YELLOW}%s", len(matchIDs), player.SummonerName)
+	return matchIDs, nil
+}
+
+func (gd *GameData) retrieveMatchList(ctx context.Context, u string) ([]string, error) {
+	b, err := gd.client.GetContext(ctx, u)
 	if err != nil {
 		return nil, err
 	}
@@ -74,15 +272,51 @@ func (gd *GameData) RetrievePlayerGamesId(player *gamedata.Player) ([]string, er
 	if err = json.Unmarshal(b, &matchIDs); err != nil {
 		return nil, err
 	}
-	printer.Info("Found {-F_MAGENTA,BOLD}%d {-RESET}games for {-F_YELLOW}%s", len(matchIDs), player.SummonerName)
 	return matchIDs, nil
 }
 
-func (gd *GameData) RetrieveGameInfo(gameID string) (*gamedata.MatchData, error) {
-	b, err := gd.client.Get(gd.em.GetMatchInfoURL(gameID))
+// soloRank returns the solo queue rank of the player, nil if unranked or
+// when the strategy doesn't score the players by rank.
+func (gd *GameData) soloRank(ctx context.Context, summonerID string) (*gamedata.LeagueEntry, error) {
+	if gd.ranks == nil {
+		return nil, nil
+	}
+	if rank, ok := gd.ranks.Load(summonerID); ok {
+		return rank.(*gamedata.LeagueEntry), nil
+	}
+	b, err := gd.client.GetContext(ctx, gd.em.GetLeagueEntriesBySummoner(summonerID))
+	if err != nil {
+		return nil, err
+	}
+	var entries []gamedata.LeagueEntry
+	if err = json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+	var rank *gamedata.LeagueEntry
+	for i := range entries {
+		if entries[i].QueueType == gamedata.RankedSolo5V5 {
+			rank = &entries[i]
+		}
+	}
+	gd.ranks.Store(summonerID, rank)
+	return rank, nil
+}
+
+// SetArchive makes the crawler keep the raw payload of every downloaded match.
+func (gd *GameData) SetArchive(a *archive.Store) {
+	gd.archive = a
+}
+
+func (gd *GameData) RetrieveGameInfo(ctx context.Context, gameID string) (*gamedata.MatchData, error) {
+	b, err := gd.client.GetContext(ctx, gd.em.GetMatchInfoURL(gameID))
 	if err != nil {
 		return nil, err
 	}
+	if gd.archive != nil {
+		if err := gd.archive.Put(gameID, b); err != nil {
+			return nil, err
+		}
+	}
 	var matchData = new(gamedata.MatchData)
 	if err = json.Unmarshal(b, matchData); err != nil {
 		return nil, err
@@ -91,7 +325,7 @@ func (gd *GameData) RetrieveGameInfo(gameID string) (*gamedata.MatchData, error)
 }
 
 func (gd *GameData) InitWithChallengerPlayers() ([]*gamedata.Player, error) {
-	b, err := gd.client.Get(gd.em.GetSummonersByLeague(gamedata.RankedSolo5V5, gamedata.Challenger, gamedata.TierOne, 1))
+	b, err := gd.client.GetContext(context.Background(), gd.em.GetSummonersByLeague(gamedata.RankedSolo5V5, gamedata.Challenger, gamedata.TierOne, 1))
 	if err != nil {
 		return nil, err
 	}
@@ -106,51 +340,224 @@ func (gd *GameData) InitWithChallengerPlayers() ([]*gamedata.Player, error) {
 	return pp, nil
 }
 
-func (gd *GameData) CrawlPlayerData(player *gamedata.Player, pool *queue.Pool) error {
-	_, ok := gd.playersCrawled.Load(player.SummonerName)
-	if ok {
+// processMatch downloads the match and saves it if it's kept by the filter.
+// It returns nil if the match has already been processed, has failed or is
+// being processed by another job. A failed match isn't processed again by
+// the run, unless it was interrupted.
+func (gd *GameData) processMatch(ctx context.Context, gameID string) (*gamedata.MatchData, error) {
+	if !gd.matches.claim(gameID) {
+		printer.Debug("Game %s already processed", gameID)
+		gd.budget.countSkipped()
+		return nil, nil
+	}
+	matchdata, saved, err := gd.fetchMatch(ctx, gameID)
+	if err == nil {
+		err = gd.state.MarkMatchProcessed(gd.platform, gameID, saved)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			gd.matches.release(gameID)
+		} else {
+			gd.matches.fail(gameID)
+		}
+		return nil, err
+	}
+	gd.matches.done(gameID, saved)
+	// Dead-lettered by a previous run, recovered through another player
+	if err := gd.state.DeleteFailedMatch(gd.platform, gameID); err != nil {
+		printer.Warn("[%s] Unable to remove the recovered game %s from the dead letters: %v", gd.platform, gameID, err)
+	}
+	return matchdata, nil
+}
+
+func (gd *GameData) fetchMatch(ctx context.Context, gameID string) (*gamedata.MatchData, bool, error) {
+	matchdata, err := gd.RetrieveGameInfo(ctx, gameID)
+	if err != nil {
+		return nil, false, err
+	}
+	gd.budget.countFetched()
+	if !matchdata.Info.QueueId.IsRanked() {
+		printer.Debug("Skipping game %s from queue %s", gameID, matchdata.Info.QueueId)
+		gd.budget.countRejected()
+		return nil, false, nil
+	}
+	if n := positions.Complete(matchdata, gd.StaticData()); n > 0 {
+		printer.Debug("Inferred the position of %d participants of %s", n, gameID)
+	}
+	if !gd.filter.KeepMatch(matchdata) {
+		gd.budget.countRejected()
+		return matchdata, false, nil
+	}
+	gd.info("{-F_GREEN,BOLD}Saving game {-RESET}%s", gameID)
+	start := time.Now()
+	if err := gd.sink.SaveMatch(matchdata); err != nil {
+		return nil, false, saveError(err)
+	}
+	gd.writes.add(time.Since(start))
+	gd.budget.countSaved(matchdata, gd.filter, gamedata.Patch(gd.StaticData().APIVersion))
+	for i := range matchdata.Info.Participants {
+		if p := &matchdata.Info.Participants[i]; gd.filter.IsTracked(p) {
+			gd.tracked.add(p.SummonerId)
+			// The match is saved, failing it would count it again on retry
+			if err := gd.state.CountTrackedGame(gd.platform, p.SummonerId); err != nil {
+				printer.Warn("[%s] Unable to count the tracked game %s of %s: %v", gd.platform, gameID, p.SummonerId, err)
+			}
+		}
+	}
+	return matchdata, true, nil
+}
+
+// recordFailure adds the match to the dead letters, to be retried later.
+func (gd *GameData) recordFailure(matchID string, err error) error {
+	gd.budget.countError(err)
+	printer.Warn("[%s] Game %s failed, added to the dead letters: %v", gd.platform, matchID, err)
+	return gd.state.RecordFailedMatch(gd.platform, matchID, ErrorKind(err), err.Error())
+}
+
+// RetryMatch processes a dead letter again, removing it on success. The
+// players of the match aren't crawled.
+func (gd *GameData) RetryMatch(ctx context.Context, matchID string) error {
+	_, saved, err := gd.fetchMatch(ctx, matchID)
+	if err == nil {
+		err = gd.state.MarkMatchProcessed(gd.platform, matchID, saved)
+	}
+	if err != nil {
+		// Interrupted, not an attempt
+		if ctx.Err() != nil {
+			return err
+		}
+		return errors.Join(err, gd.recordFailure(matchID, err))
+	}
+	return gd.state.DeleteFailedMatch(gd.platform, matchID)
+}
+
+// CrawlPlayerData crawls the matches of the player not visited yet, and
+// dispatches the crawl of the players found in them.
+func (gd *GameData) CrawlPlayerData(ctx context.Context, player *gamedata.Player, pool *queue.Pool) error {
+	if gd.budget.exhausted() {
 		return nil
 	}
-	gd.playersCrawled.Store(player.SummonerName, true)
+	if _, visited := gd.visited.LoadOrStore(player.SummonerId, true); visited {
+		return gd.state.RemoveFromFrontier(gd.platform, player.SummonerId)
+	}
+	return gd.crawlPlayer(ctx, player, pool)
+}
+
+// RefreshPlayerData crawls the matches played by a visited player since its
+// last crawl. The players found in them are added to the frontier, for the
+// next crawl.
+func (gd *GameData) RefreshPlayerData(ctx context.Context, player *gamedata.Player) error {
+	if gd.budget.exhausted() {
+		return nil
+	}
+	return gd.crawlPlayer(ctx, player, nil)
+}
+
+// crawlPlayer processes the matches of the player, dispatching the players
+// found in them to the pool, if any. When the context is done, it stops with
+// the error of the job deadline, without error on shutdown, the player
+// staying in the frontier.
+func (gd *GameData) crawlPlayer(ctx context.Context, player *gamedata.Player, pool *queue.Pool) error {
+	refresh := pool == nil
+	// Crawled again by the next run
+	forget := func() {
+		if !refresh {
+			gd.visited.Delete(player.SummonerId)
+		}
+	}
+	listedAt := time.Now()
 	if player.Puuid == "" {
-		if err := gd.RetrieveAdditionalPlayerData(player); err != nil {
+		if err := gd.RetrieveAdditionalPlayerData(ctx, player); err != nil {
+			forget()
+			if ctx.Err() != nil {
+				return stopped(ctx)
+			}
 			return err
 		}
 		printer.Debug("Retrieved additional data for player %s", player.SummonerName)
 	}
-	gameIds, err := gd.RetrievePlayerGamesId(player)
+	gameIds, err := gd.RetrievePlayerGamesId(ctx, player)
 	if err != nil {
+		forget()
+		if ctx.Err() != nil {
+			return stopped(ctx)
+		}
 		return err
 	}
 	for _, g := range gameIds {
-		matchdata, err := gd.RetrieveGameInfo(g)
+		if gd.budget.exhausted() || ctx.Err() != nil {
+			forget()
+			return stopped(ctx)
+		}
+		matchdata, err := gd.processMatch(ctx, g)
 		if err != nil {
-			return err
+			if ctx.Err() != nil {
+				forget()
+				return stopped(ctx)
+			}
+			if err := gd.recordFailure(g, err); err != nil {
+				forget()
+				return err
+			}
+			continue
 		}
-		if matchdata.Info.GameMode != gamedata.GameModeClassic || matchdata.Info.GameType != gamedata.GameTypeRanked {
+		if matchdata == nil {
 			continue
 		}
-		for _, p := range matchdata.Info.Participants {
-			if p.Role != gamedata.RoleCarry || p.Lane != gamedata.LaneBottom {
+		for i := range matchdata.Info.Participants {
+			p := &matchdata.Info.Participants[i]
+			if !gd.filter.Follow(p) || p.SummonerId == player.SummonerId {
 				continue
 			}
-			if p.ChampionId == gd.lookingChampID {
-				printer.Info("{-F_GREEN,BOLD}Saving game")
-				if err := gd.db.SaveMatch(matchdata); err != nil {
-					return err
+			if _, visited := gd.visited.Load(p.SummonerId); visited {
+				continue
+			}
+			newPlayer := gamedata.Player{
+				SummonerId:    p.SummonerId,
+				SummonerName:  p.SummonerName,
+				SummonerLevel: p.SummonerLevel,
+				Puuid:         p.Puuid,
+			}
+			rank, err := gd.soloRank(ctx, p.SummonerId)
+			if err != nil {
+				if ctx.Err() != nil {
+					forget()
+					return stopped(ctx)
 				}
+				printer.Debug("Unable to retrieve the rank of %s, estimated: %v", p.SummonerName, err)
+			}
+			newPlayer.Priority = gd.strategy.Score(&Candidate{
+				From:         player,
+				Participant:  p,
+				Match:        matchdata,
+				TrackedGames: gd.tracked.get(p.SummonerId),
+				Rank:         rank,
+			})
+			if err := gd.state.AddToFrontier(gd.platform, &newPlayer, newPlayer.Priority); err != nil {
+				return err
 			}
-			if p.SummonerId != player.SummonerId {
-				newPlayer := gamedata.Player{
-					SummonerId:    p.SummonerId,
-					SummonerName:  p.SummonerName,
-					SummonerLevel: p.SummonerLevel,
+			if !refresh {
+				j, err := gd.PlayerJob(JobCrawlPlayer, &newPlayer)
+				if err != nil {
+					return err
 				}
-				pool.Dispatch(func() error {
-					return gd.CrawlPlayerData(&newPlayer, pool)
-				})
+				pool.Dispatch(j)
 			}
 		}
 	}
+	player.LastMatchTime = listedAt.Add(-refreshOverlap).UnixMilli()
+	if err := gd.state.MarkVisited(gd.platform, player); err != nil {
+		return err
+	}
+	gd.budget.countPlayer()
+	return gd.state.RemoveFromFrontier(gd.platform, player.SummonerId)
+}
+
+// stopped returns the error of a crawl stopped early: the deadline of its job
+// having passed, or nil when the budget is reached or the pool shuts down.
+func stopped(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ctx.Err()
+	}
 	return nil
 }
@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"sync"
+
+	"LoLItemRecommender/internal/database"
+)
+
+type matchState int
+
+const (
+	matchUnknown matchState = iota
+	matchInFlight
+	matchSaved
+	matchRejected
+	// Dead-lettered, left to the retry command for the rest of the run
+	matchFailed
+)
+
+// matchIndex remembers the matches already downloaded, so a match shared by
+// several crawled players is fetched only once.
+type matchIndex struct {
+	mu     sync.Mutex
+	states map[string]matchState
+}
+
+func newMatchIndex(processed []database.ProcessedMatch) *matchIndex {
+	idx := &matchIndex{states: make(map[string]matchState, len(processed))}
+	for _, m := range processed {
+		idx.states[m.MatchUID] = matchRejected
+		if m.Saved {
+			idx.states[m.MatchUID] = matchSaved
+		}
+	}
+	return idx
+}
+
+// claim reports whether the match has to be fetched. If so, the match is
+// marked in flight until done, fail or release is called.
+func (idx *matchIndex) claim(matchID string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.states[matchID] != matchUnknown {
+		return false
+	}
+	idx.states[matchID] = matchInFlight
+	return true
+}
+
+// release forgets a claimed match whose processing was interrupted.
+func (idx *matchIndex) release(matchID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.states, matchID)
+}
+
+// fail marks a claimed match as failed, so the other players of the match
+// don't count another attempt.
+func (idx *matchIndex) fail(matchID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.states[matchID] = matchFailed
+}
+
+func (idx *matchIndex) done(matchID string, saved bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.states[matchID] = matchRejected
+	if saved {
+		idx.states[matchID] = matchSaved
+	}
+}
+
+// counts returns the number of saved and rejected matches.
+func (idx *matchIndex) counts() (saved, rejected int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, s := range idx.states {
+		switch s {
+		case matchSaved:
+			saved++
+		case matchRejected:
+			rejected++
+		}
+	}
+	return saved, rejected
+}
@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"testing"
+
+	"LoLItemRecommender/internal/database"
+)
+
+func TestMatchIndex(t *testing.T) {
+	idx := newMatchIndex([]database.ProcessedMatch{
+		{MatchUID: "EUW1_1", Saved: true},
+		{MatchUID: "EUW1_2"},
+	})
+	for _, id := range []string{"EUW1_1", "EUW1_2"} {
+		if idx.claim(id) {
+			t.Errorf("claim(%s) = true for a processed match", id)
+		}
+	}
+
+	if !idx.claim("EUW1_3") {
+		t.Fatal("claim(EUW1_3) = false for a new match")
+	}
+	if idx.claim("EUW1_3") {
+		t.Error("claim(EUW1_3) = true for a match in flight")
+	}
+	// Interrupted, fetched again by the next player
+	idx.release("EUW1_3")
+	if !idx.claim("EUW1_3") {
+		t.Error("claim(EUW1_3) = false for a released match")
+	}
+	// Dead-lettered, left to the retry command
+	idx.fail("EUW1_3")
+	if idx.claim("EUW1_3") {
+		t.Error("claim(EUW1_3) = true for a failed match")
+	}
+
+	idx.claim("EUW1_4")
+	idx.done("EUW1_4", true)
+	if saved, rejected := idx.counts(); saved != 2 || rejected != 1 {
+		t.Errorf("counts() = %d saved, %d rejected, want 2 and 1", saved, rejected)
+	}
+}
@@ -0,0 +1,186 @@
+package crawler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"LoLItemRecommender/internal/config"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/printer"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// MatchSink is a destination of the matches kept by the crawler.
+type MatchSink interface {
+	SaveMatch(match *gamedata.MatchData) error
+	Close() error
+}
+
+// mysqlSink saves the matches to the database, which is closed by its owner.
+type mysqlSink struct {
+	*database.DB
+}
+
+func (s mysqlSink) Close() error {
+	return nil
+}
+
+// jsonSink writes one JSON match per line, each match once, so that a match
+// saved again after another sink failed isn't duplicated.
+type jsonSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	// IDs of the matches written
+	written map[string]bool
+	// Flushed after each match so a killed crawl doesn't lose any match
+	flush  func() error
+	closer func() error
+}
+
+func (s *jsonSink) SaveMatch(match *gamedata.MatchData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := match.Metadata.MatchId
+	if s.written[id] {
+		return nil
+	}
+	if err := s.enc.Encode(match); err != nil {
+		return err
+	}
+	if err := s.flush(); err != nil {
+		return err
+	}
+	s.written[id] = true
+	return nil
+}
+
+func (s *jsonSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closer()
+}
+
+// NewNDJSONSink appends the matches to the gzipped NDJSON file at path. Each
+// run adds a gzip member to the file, which gzip readers read as a whole. The
+// matches already in the file aren't appended again.
+func NewNDJSONSink(path string) (MatchSink, error) {
+	written, err := readMatchIDs(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(f)
+	return &jsonSink{
+		enc:     json.NewEncoder(gz),
+		written: written,
+		flush:   gz.Flush,
+		closer: func() error {
+			return errors.Join(gz.Close(), f.Close())
+		},
+	}, nil
+}
+
+// readMatchIDs returns the IDs of the matches of the gzipped NDJSON file, if
+// it exists. The matches after a truncated member, left by a killed crawl,
+// are ignored.
+func readMatchIDs(path string) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err == io.EOF {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(gz)
+	for {
+		var m struct {
+			Metadata struct {
+				MatchId string `json:"matchId"`
+			} `json:"metadata"`
+		}
+		if err := dec.Decode(&m); err != nil {
+			if err != io.EOF {
+				printer.Warn("Matches of %s read up to an error: %v", path, err)
+			}
+			return ids, nil
+		}
+		ids[m.Metadata.MatchId] = true
+	}
+}
+
+// NewStdoutSink writes the matches to the standard output, the logs being
+// moved to the standard error.
+func NewStdoutSink() MatchSink {
+	printer.SetOutput(os.Stderr)
+	return newWriterSink(os.Stdout)
+}
+
+func newWriterSink(w io.Writer) *jsonSink {
+	noop := func() error { return nil }
+	return &jsonSink{enc: json.NewEncoder(w), written: make(map[string]bool), flush: noop, closer: noop}
+}
+
+// FanOut saves the matches to every sink. The sinks being idempotent, a match
+// failing on one sink is saved again to all of them.
+type FanOut []MatchSink
+
+func (f FanOut) SaveMatch(match *gamedata.MatchData) error {
+	errs := make([]error, 0, len(f))
+	for _, s := range f {
+		errs = append(errs, s.SaveMatch(match))
+	}
+	return errors.Join(errs...)
+}
+
+func (f FanOut) Close() error {
+	errs := make([]error, 0, len(f))
+	for _, s := range f {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// NewSink returns the sinks of the configuration, db being used by the mysql
+// sink.
+func NewSink(sinks []config.Sink, db *database.DB) (MatchSink, error) {
+	f := make(FanOut, 0, len(sinks))
+	for _, s := range sinks {
+		var (
+			sink MatchSink
+			err  error
+		)
+		switch s.Type {
+		case config.SinkMySQL:
+			sink = mysqlSink{db}
+		case config.SinkNDJSON:
+			sink, err = NewNDJSONSink(s.Path)
+		case config.SinkStdout:
+			sink = NewStdoutSink()
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		f = append(f, sink)
+	}
+	if len(f) == 1 {
+		return f[0], nil
+	}
+	return f, nil
+}
@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+func matchWithID(id string) *gamedata.MatchData {
+	m := &gamedata.MatchData{}
+	m.Metadata.MatchId = id
+	return m
+}
+
+// failingSink fails the first fails saves.
+type failingSink struct {
+	fails int
+	saved int
+}
+
+func (s *failingSink) SaveMatch(*gamedata.MatchData) error {
+	if s.fails > 0 {
+		s.fails--
+		return errors.New("unavailable")
+	}
+	s.saved++
+	return nil
+}
+
+func (s *failingSink) Close() error {
+	return nil
+}
+
+func TestFanOutRetry(t *testing.T) {
+	var out bytes.Buffer
+	failing := &failingSink{fails: 1}
+	f := FanOut{newWriterSink(&out), failing}
+	m := matchWithID("EUW1_1")
+	if err := f.SaveMatch(m); err == nil {
+		t.Fatal("SaveMatch() = nil, want the error of the failing sink")
+	}
+	if err := f.SaveMatch(m); err != nil {
+		t.Fatalf("SaveMatch() retry = %v", err)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 1 {
+		t.Errorf("match written %d times, want once", n)
+	}
+	if failing.saved != 1 {
+		t.Errorf("match saved %d times by the failing sink, want once", failing.saved)
+	}
+}
+
+func TestNDJSONSinkAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matches.ndjson.gz")
+	for run, ids := range [][]string{{"EUW1_1", "EUW1_2", "EUW1_1"}, {"EUW1_2", "EUW1_3"}} {
+		s, err := NewNDJSONSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range ids {
+			if err := s.SaveMatch(matchWithID(id)); err != nil {
+				t.Fatalf("run %d: SaveMatch(%s) = %v", run, id, err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := b.ReadFrom(gz); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"EUW1_1", "EUW1_2", "EUW1_3"} {
+		if n := strings.Count(b.String(), `"`+id+`"`); n != 1 {
+			t.Errorf("%s written %d times, want once", id, n)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package crawler
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// StateStore keeps the progress of the crawl of each platform: the players to
+// crawl, the visited players and the processed matches.
+type StateStore interface {
+	AddToFrontier(platform string, player *gamedata.Player, priority int) error
+	RemoveFromFrontier(platform, summonerID string) error
+	GetFrontier(platform string) ([]*gamedata.Player, error)
+	MarkVisited(platform string, player *gamedata.Player) error
+	GetVisited(platform string) ([]string, error)
+	// Visited players to refresh, the ones playing the tracked champions the
+	// most first
+	GetRefreshPlayers(platform string) ([]*gamedata.Player, error)
+	CountTrackedGame(platform, summonerID string) error
+	GetTrackedPlayers(platform string) ([]database.TrackedPlayer, error)
+	// Matches saved on the patch per champion and position
+	CountPatchMatches(platform, patch string) ([]database.PatchMatchCount, error)
+	GetProcessedMatches(platform string) ([]database.ProcessedMatch, error)
+	MarkMatchProcessed(platform, matchID string, saved bool) error
+	// Dead letters, the matches which failed to be processed
+	RecordFailedMatch(platform, matchID, kind, message string) error
+	DeleteFailedMatch(platform, matchID string) error
+	SavePatchBoundary(previous, current string) error
+}
+
+type frontierEntry struct {
+	platform string
+	player   gamedata.Player
+	// Insertion order, the oldest players being crawled first
+	seq int
+}
+
+type visitedEntry struct {
+	platform string
+	player   gamedata.Player
+}
+
+// stateKey returns the key of a player in the maps of MemoryState, the
+// summoner IDs being unique within a platform only.
+func stateKey(platform, summonerID string) string {
+	return platform + "/" + summonerID
+}
+
+// MemoryState is a StateStore lost when the crawl stops, used when there is
+// no database.
+type MemoryState struct {
+	mu        sync.Mutex
+	seq       int
+	frontier  map[string]*frontierEntry
+	visited   map[string]*visitedEntry
+	tracked   map[string]int
+	processed map[string]bool
+	failed    map[string]*database.FailedMatch
+}
+
+func NewMemoryState() *MemoryState {
+	return &MemoryState{
+		frontier:  make(map[string]*frontierEntry),
+		visited:   make(map[string]*visitedEntry),
+		tracked:   make(map[string]int),
+		processed: make(map[string]bool),
+		failed:    make(map[string]*database.FailedMatch),
+	}
+}
+
+func (m *MemoryState) AddToFrontier(platform string, player *gamedata.Player, priority int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := stateKey(platform, player.SummonerId)
+	if e, ok := m.frontier[key]; ok {
+		if priority > e.player.Priority {
+			e.player.Priority = priority
+		}
+		return nil
+	}
+	m.seq++
+	e := &frontierEntry{platform: platform, player: *player, seq: m.seq}
+	e.player.Priority = priority
+	m.frontier[key] = e
+	return nil
+}
+
+func (m *MemoryState) RemoveFromFrontier(platform, summonerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.frontier, stateKey(platform, summonerID))
+	return nil
+}
+
+func (m *MemoryState) GetFrontier(platform string) ([]*gamedata.Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := make([]*frontierEntry, 0)
+	for _, e := range m.frontier {
+		if e.platform == platform {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].player.Priority != entries[j].player.Priority {
+			return entries[i].player.Priority > entries[j].player.Priority
+		}
+		return entries[i].seq < entries[j].seq
+	})
+	players := make([]*gamedata.Player, len(entries))
+	for i, e := range entries {
+		p := e.player
+		players[i] = &p
+	}
+	return players, nil
+}
+
+func (m *MemoryState) MarkVisited(platform string, player *gamedata.Player) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := stateKey(platform, player.SummonerId)
+	e := &visitedEntry{platform: platform, player: *player}
+	if prev, ok := m.visited[key]; ok && prev.player.LastMatchTime > player.LastMatchTime {
+		e.player.LastMatchTime = prev.player.LastMatchTime
+	}
+	m.visited[key] = e
+	return nil
+}
+
+func (m *MemoryState) GetVisited(platform string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0)
+	for _, e := range m.visited {
+		if e.platform == platform {
+			ids = append(ids, e.player.SummonerId)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MemoryState) GetRefreshPlayers(platform string) ([]*gamedata.Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	players := make([]*gamedata.Player, 0)
+	for _, e := range m.visited {
+		if e.platform == platform && e.player.Puuid != "" {
+			p := e.player
+			p.TrackedGames = m.tracked[stateKey(platform, p.SummonerId)]
+			players = append(players, &p)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].TrackedGames != players[j].TrackedGames {
+			return players[i].TrackedGames > players[j].TrackedGames
+		}
+		return players[i].LastMatchTime < players[j].LastMatchTime
+	})
+	return players, nil
+}
+
+func (m *MemoryState) CountTrackedGame(platform, summonerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tracked[stateKey(platform, summonerID)]++
+	return nil
+}
+
+func (m *MemoryState) GetTrackedPlayers(platform string) ([]database.TrackedPlayer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := stateKey(platform, "")
+	players := make([]database.TrackedPlayer, 0)
+	for key, n := range m.tracked {
+		if strings.HasPrefix(key, prefix) {
+			players = append(players, database.TrackedPlayer{SummonerID: strings.TrimPrefix(key, prefix), TrackedGames: n})
+		}
+	}
+	return players, nil
+}
+
+// CountPatchMatches returns no match, the matches saved by the previous runs
+// being unknown.
+func (m *MemoryState) CountPatchMatches(platform, patch string) ([]database.PatchMatchCount, error) {
+	return nil, nil
+}
+
+func (m *MemoryState) GetProcessedMatches(platform string) ([]database.ProcessedMatch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := strings.ToUpper(platform) + "_"
+	matches := make([]database.ProcessedMatch, 0)
+	for id, saved := range m.processed {
+		if strings.HasPrefix(id, prefix) {
+			matches = append(matches, database.ProcessedMatch{MatchUID: id, Saved: saved})
+		}
+	}
+	return matches, nil
+}
+
+func (m *MemoryState) MarkMatchProcessed(_, matchID string, saved bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processed[matchID] = saved
+	return nil
+}
+
+func (m *MemoryState) RecordFailedMatch(platform, matchID, kind, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	f, ok := m.failed[matchID]
+	if !ok {
+		f = &database.FailedMatch{MatchUID: matchID, Platform: platform, FirstFailedAt: now}
+		m.failed[matchID] = f
+	}
+	f.Kind = kind
+	f.Error = message
+	f.Attempts++
+	f.LastFailedAt = now
+	return nil
+}
+
+func (m *MemoryState) DeleteFailedMatch(_, matchID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.failed, matchID)
+	return nil
+}
+
+// FailedMatches returns the dead letters, lost when the crawl stops.
+func (m *MemoryState) FailedMatches() []database.FailedMatch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	failed := make([]database.FailedMatch, 0, len(m.failed))
+	for _, f := range m.failed {
+		failed = append(failed, *f)
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].MatchUID < failed[j].MatchUID
+	})
+	return failed
+}
+
+func (m *MemoryState) SavePatchBoundary(_, _ string) error {
+	return nil
+}
@@ -0,0 +1,389 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+func (d *DB) createTableCrawlFrontier() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS crawl_frontier (
+		    summoner_id VARCHAR(255) NOT NULL,
+		    platform_id VARCHAR(16) NOT NULL,
+		    summoner_name VARCHAR(255) NOT NULL,
+		    summoner_level INT NOT NULL,
+		    puuid VARCHAR(255) NOT NULL DEFAULT '',
+		    priority INT NOT NULL,
+			added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		    PRIMARY KEY (platform_id, summoner_id),
+		    INDEX (platform_id, priority, added_at)
+		);
+	`
+
+	_, err := d.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	// Crawls before the multi-platform support were on euw1 only
+	if err := d.addColumnIfMissing("crawl_frontier", "platform_id", "VARCHAR(16) NOT NULL DEFAULT 'euw1' AFTER summoner_id"); err != nil {
+		return err
+	}
+	return d.keyOnPlatform("crawl_frontier")
+}
+
+// keyOnPlatform adds the platform to the primary key of a table keyed by
+// summoner ID only by an older version, the summoner IDs being unique within
+// a platform only.
+func (d *DB) keyOnPlatform(table string) error {
+	keyed, err := d.primaryKeyHas(table, "platform_id")
+	if err != nil || keyed {
+		return err
+	}
+	_, err = d.db.Exec(fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY, ADD PRIMARY KEY (platform_id, summoner_id)", table))
+	if err != nil {
+		return fmt.Errorf("can't change primary key of %s: %w", table, err)
+	}
+	return nil
+}
+
+func (d *DB) createTableCrawlVisited() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS crawl_visited (
+		    summoner_id VARCHAR(255) NOT NULL,
+		    platform_id VARCHAR(16) NOT NULL,
+		    summoner_name VARCHAR(255) NOT NULL DEFAULT '',
+		    summoner_level INT NOT NULL DEFAULT 0,
+		    puuid VARCHAR(255) NOT NULL DEFAULT '',
+		    last_match_time BIGINT NOT NULL DEFAULT 0,
+			last_crawled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		    PRIMARY KEY (platform_id, summoner_id)
+		);
+	`
+
+	_, err := d.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	columns := [][2]string{
+		{"platform_id", "VARCHAR(16) NOT NULL DEFAULT 'euw1' AFTER summoner_id"},
+		{"summoner_name", "VARCHAR(255) NOT NULL DEFAULT '' AFTER platform_id"},
+		{"summoner_level", "INT NOT NULL DEFAULT 0 AFTER summoner_name"},
+		{"puuid", "VARCHAR(255) NOT NULL DEFAULT '' AFTER summoner_level"},
+		{"last_match_time", "BIGINT NOT NULL DEFAULT 0 AFTER puuid"},
+	}
+	for _, c := range columns {
+		if err := d.addColumnIfMissing("crawl_visited", c[0], c[1]); err != nil {
+			return err
+		}
+	}
+	return d.keyOnPlatform("crawl_visited")
+}
+
+func (d *DB) createTableTrackedPlayers() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS tracked_players (
+		    summoner_id VARCHAR(255) NOT NULL,
+		    platform_id VARCHAR(16) NOT NULL,
+		    tracked_games INT NOT NULL DEFAULT 0,
+		    PRIMARY KEY (platform_id, summoner_id),
+		    INDEX (platform_id, tracked_games)
+		);
+	`
+
+	_, err := d.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	return d.keyOnPlatform("tracked_players")
+}
+
+func (d *DB) createTableProcessedMatches() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS processed_matches (
+		    match_uid VARCHAR(255) PRIMARY KEY,
+		    platform_id VARCHAR(16) NOT NULL DEFAULT '',
+		    saved BOOLEAN NOT NULL DEFAULT FALSE,
+			processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+	`
+
+	_, err := d.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("processed_matches", "saved", "BOOLEAN NOT NULL DEFAULT FALSE AFTER match_uid"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("processed_matches", "platform_id", "VARCHAR(16) NOT NULL DEFAULT '' AFTER match_uid"); err != nil {
+		return err
+	}
+	// Match IDs are prefixed by the platform, e.g. EUW1_6543210987
+	_, err = d.db.Exec(`
+		UPDATE processed_matches SET platform_id = LOWER(SUBSTRING_INDEX(match_uid, '_', 1))
+		WHERE platform_id = ''`)
+	if err != nil {
+		return fmt.Errorf("can't set platform of processed matches: %w", err)
+	}
+	return nil
+}
+
+// AddToFrontier saves a player of the platform waiting to be crawled. A
+// player already in the frontier keeps the highest of the two priorities.
+func (d *DB) AddToFrontier(platform string, player *gamedata.Player, priority int) error {
+	_, err := d.db.Exec(`
+		INSERT INTO crawl_frontier (summoner_id, platform_id, summoner_name, summoner_level, puuid, priority)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			priority=GREATEST(priority, VALUES(priority))`,
+		player.SummonerId, platform, player.SummonerName, player.SummonerLevel, player.Puuid, priority)
+	if err != nil {
+		return fmt.Errorf("can't add player to the frontier: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) RemoveFromFrontier(platform, summonerID string) error {
+	_, err := d.db.Exec(`DELETE FROM crawl_frontier WHERE platform_id = ? AND summoner_id = ?`, platform, summonerID)
+	if err != nil {
+		return fmt.Errorf("can't remove player from the frontier: %w", err)
+	}
+	return nil
+}
+
+// GetFrontier returns the players of the platform waiting to be crawled, the
+// highest priority first, then the oldest.
+func (d *DB) GetFrontier(platform string) ([]*gamedata.Player, error) {
+	var p []*gamedata.Player
+	err := d.db.Select(&p, `
+		SELECT summoner_id AS id, summoner_name AS name, summoner_level AS level, puuid, priority
+		FROM crawl_frontier
+		WHERE platform_id = ?
+		ORDER BY priority DESC, added_at`, platform)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MarkVisited records that the matches of the player played before its
+// LastMatchTime have been processed.
+func (d *DB) MarkVisited(platform string, player *gamedata.Player) error {
+	_, err := d.db.Exec(`
+		INSERT INTO crawl_visited (summoner_id, platform_id, summoner_name, summoner_level, puuid, last_match_time)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			summoner_name=VALUES(summoner_name),
+			summoner_level=VALUES(summoner_level),
+			puuid=VALUES(puuid),
+			last_match_time=GREATEST(last_match_time, VALUES(last_match_time)),
+			last_crawled_at=CURRENT_TIMESTAMP`,
+		player.SummonerId, platform, player.SummonerName, player.SummonerLevel, player.Puuid, player.LastMatchTime)
+	if err != nil {
+		return fmt.Errorf("can't mark player as visited: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) GetVisited(platform string) ([]string, error) {
+	var ids []string
+	if err := d.db.Select(&ids, `SELECT summoner_id FROM crawl_visited WHERE platform_id = ?`, platform); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetRefreshPlayers returns the visited players of the platform, the ones
+// playing the tracked champions the most first, then the least recently
+// refreshed.
+func (d *DB) GetRefreshPlayers(platform string) ([]*gamedata.Player, error) {
+	var p []*gamedata.Player
+	err := d.db.Select(&p, `
+		SELECT v.summoner_id AS id, v.summoner_name AS name, v.summoner_level AS level, v.puuid,
+			v.last_match_time, COALESCE(t.tracked_games, 0) AS tracked_games
+		FROM crawl_visited v
+		LEFT JOIN tracked_players t ON t.platform_id = v.platform_id AND t.summoner_id = v.summoner_id
+		WHERE v.platform_id = ? AND v.puuid != ''
+		ORDER BY tracked_games DESC, v.last_match_time`, platform)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CountTrackedGame counts a crawled match in which the player played a
+// tracked champion.
+func (d *DB) CountTrackedGame(platform, summonerID string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO tracked_players (summoner_id, platform_id, tracked_games) VALUES (?, ?, 1)
+		ON DUPLICATE KEY UPDATE tracked_games=tracked_games+1`, summonerID, platform)
+	if err != nil {
+		return fmt.Errorf("can't count tracked game: %w", err)
+	}
+	return nil
+}
+
+// TrackedPlayer is a player with the crawled matches in which it played a
+// tracked champion.
+type TrackedPlayer struct {
+	SummonerID   string `db:"summoner_id"`
+	TrackedGames int    `db:"tracked_games"`
+}
+
+// GetTrackedPlayers returns the players of the platform who played a tracked
+// champion in the crawled matches.
+func (d *DB) GetTrackedPlayers(platform string) ([]TrackedPlayer, error) {
+	var t []TrackedPlayer
+	err := d.db.Select(&t, `SELECT summoner_id, tracked_games FROM tracked_players WHERE platform_id = ?`, platform)
+	if err != nil {
+		return nil, fmt.Errorf("can't get tracked players: %w", err)
+	}
+	return t, nil
+}
+
+// PatchMatchCount is the number of matches saved on a patch in which a
+// champion was played in a position.
+type PatchMatchCount struct {
+	ChampionID int    `db:"champion_id"`
+	Position   string `db:"position"`
+	Matches    int    `db:"matches"`
+}
+
+// CountPatchMatches returns the number of matches of the platform saved on
+// the patch, e.g. 14.3, per champion and position. The position is the one
+// given by Riot, the inferred one otherwise.
+func (d *DB) CountPatchMatches(platform, patch string) ([]PatchMatchCount, error) {
+	var c []PatchMatchCount
+	err := d.db.Select(&c, `
+		SELECT p.champion_id,
+			CASE
+				WHEN p.team_position IN ('TOP', 'JUNGLE', 'MIDDLE', 'BOTTOM', 'UTILITY') THEN p.team_position
+				WHEN p.individual_position IN ('TOP', 'JUNGLE', 'MIDDLE', 'BOTTOM', 'UTILITY') THEN p.individual_position
+				ELSE p.inferred_position
+			END AS position,
+			COUNT(*) AS matches
+		FROM matches m
+		JOIN participants p ON p.platform_id = m.platform_id AND p.match_id = m.id
+		WHERE m.platform_id = ? AND (m.version = ? OR m.version LIKE ?)
+		GROUP BY p.champion_id, position`, strings.ToUpper(platform), patch, patch+".%")
+	if err != nil {
+		return nil, fmt.Errorf("can't count matches of patch %s: %w", patch, err)
+	}
+	return c, nil
+}
+
+// ProcessedMatch is a match already downloaded, Saved being false when it was
+// rejected by the filters.
+type ProcessedMatch struct {
+	MatchUID string `db:"match_uid"`
+	Saved    bool   `db:"saved"`
+}
+
+// GetProcessedMatches returns the processed matches of the platform,
+// including the ones saved before they were recorded in processed_matches.
+// Match IDs are prefixed by the platform, e.g. EUW1_6543210987.
+func (d *DB) GetProcessedMatches(platform string) ([]ProcessedMatch, error) {
+	var m []ProcessedMatch
+	prefix := strings.ToUpper(platform) + `\_%`
+	err := d.db.Select(&m, `
+		SELECT match_uid, saved FROM processed_matches
+		WHERE match_uid LIKE ?
+		UNION
+		SELECT match_uid, TRUE FROM matches
+		WHERE platform_id = ? AND match_uid NOT IN (SELECT match_uid FROM processed_matches)`,
+		prefix, strings.ToUpper(platform))
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MarkMatchProcessed records that the match has been downloaded and handled,
+// and whether it has been saved.
+func (d *DB) MarkMatchProcessed(platform, matchID string, saved bool) error {
+	_, err := d.db.Exec(`
+		INSERT INTO processed_matches (match_uid, platform_id, saved) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE saved=VALUES(saved)`, matchID, platform, saved)
+	if err != nil {
+		return fmt.Errorf("can't mark match as processed: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) createTableFailedMatches() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS failed_matches (
+		    match_uid VARCHAR(255) PRIMARY KEY,
+		    platform_id VARCHAR(16) NOT NULL,
+		    kind VARCHAR(32) NOT NULL,
+		    error TEXT NOT NULL,
+		    attempts INT NOT NULL DEFAULT 1,
+			first_failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		    INDEX (platform_id)
+		);
+	`
+
+	_, err := d.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// FailedMatch is a match which couldn't be downloaded or saved, waiting to be
+// retried.
+type FailedMatch struct {
+	MatchUID      string    `db:"match_uid"`
+	Platform      string    `db:"platform_id"`
+	Kind          string    `db:"kind"`
+	Error         string    `db:"error"`
+	Attempts      int       `db:"attempts"`
+	FirstFailedAt time.Time `db:"first_failed_at"`
+	LastFailedAt  time.Time `db:"last_failed_at"`
+}
+
+// RecordFailedMatch adds the match to the dead letters, or counts one more
+// attempt if it's already there.
+func (d *DB) RecordFailedMatch(platform, matchID, kind, message string) error {
+	// Set here rather than by MySQL, to be compared with the local clock
+	now := time.Now().UTC()
+	_, err := d.db.Exec(`
+		INSERT INTO failed_matches (match_uid, platform_id, kind, error, first_failed_at, last_failed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			kind=VALUES(kind),
+			error=VALUES(error),
+			attempts=attempts+1,
+			last_failed_at=VALUES(last_failed_at)`,
+		matchID, platform, kind, message, now, now)
+	if err != nil {
+		return fmt.Errorf("can't record failed match: %w", err)
+	}
+	return nil
+}
+
+// GetFailedMatches returns the dead letters of the platform, of every
+// platform if empty, the oldest failure first.
+func (d *DB) GetFailedMatches(platform string) ([]FailedMatch, error) {
+	var m []FailedMatch
+	err := d.db.Select(&m, `
+		SELECT match_uid, platform_id, kind, error, attempts, first_failed_at, last_failed_at
+		FROM failed_matches
+		WHERE ? = '' OR platform_id = ?
+		ORDER BY last_failed_at`, platform, platform)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (d *DB) DeleteFailedMatch(platform, matchID string) error {
+	_, err := d.db.Exec(`DELETE FROM failed_matches WHERE platform_id = ? AND match_uid = ?`, platform, matchID)
+	if err != nil {
+		return fmt.Errorf("can't delete failed match: %w", err)
+	}
+	return nil
+}
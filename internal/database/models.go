@@ -1,22 +1,48 @@
 package database
 
+import (
+	"database/sql"
+	"fmt"
+)
+
 type Participant struct {
-	ID                          int64  `json:"id"`
-	ParticipantID               int    `json:"participant_id" db:"participant_id"`
-	MatchID                     int64  `json:"match_id" db:"match_id"`
-	SummonerID                  string `json:"summoner_id" db:"summoner_id"`
-	ChampionID                  int    `json:"champion_id" db:"champion_id"`
-	TeamID                      int    `json:"team_id" db:"team_id"`
-	Role                        string `json:"role" db:"role"`
-	Lane                        string `json:"lane" db:"lane"`
-	Kills                       int    `json:"kills" db:"kills"`
-	Deaths                      int    `json:"deaths" db:"deaths"`
-	Assists                     int    `json:"assists" db:"assists"`
-	ChampLevel                  int    `json:"champ_level" db:"champ_level"`
-	TotalDamageDealtToChampions int    `json:"total_damage_dealt_to_champions" db:"total_damage_dealt_to_champions"`
-	GoldEarned                  int    `json:"gold_earned" db:"gold_earned"`
-	Win                         bool   `json:"win" db:"win"`
-	SummonerLevel               int    `json:"summoner_level" db:"summoner_level"`
-	SummonerSpell1ID            int    `json:"summoner_spell1_id" db:"summoner_spell1_id"`
-	SummonerSpell2ID            int    `json:"summoner_spell2_id" db:"summoner_spell2_id"`
+	ID                          int64   `json:"id"`
+	ParticipantID               int     `json:"participant_id" db:"participant_id"`
+	MatchID                     int64   `json:"match_id" db:"match_id"`
+	PlatformID                  string  `json:"platform_id" db:"platform_id"`
+	SummonerID                  string  `json:"summoner_id" db:"summoner_id"`
+	ChampionID                  int     `json:"champion_id" db:"champion_id"`
+	TeamID                      int     `json:"team_id" db:"team_id"`
+	Role                        string  `json:"role" db:"role"`
+	Lane                        string  `json:"lane" db:"lane"`
+	TeamPosition                string  `json:"team_position" db:"team_position"`
+	IndividualPosition          string  `json:"individual_position" db:"individual_position"`
+	InferredPosition            string  `json:"inferred_position" db:"inferred_position"`
+	PositionConfidence          float64 `json:"position_confidence" db:"position_confidence"`
+	Kills                       int     `json:"kills" db:"kills"`
+	Deaths                      int     `json:"deaths" db:"deaths"`
+	Assists                     int     `json:"assists" db:"assists"`
+	ChampLevel                  int     `json:"champ_level" db:"champ_level"`
+	TotalDamageDealtToChampions int     `json:"total_damage_dealt_to_champions" db:"total_damage_dealt_to_champions"`
+	GoldEarned                  int     `json:"gold_earned" db:"gold_earned"`
+	Win                         bool    `json:"win" db:"win"`
+	SummonerLevel               int     `json:"summoner_level" db:"summoner_level"`
+	SummonerSpell1ID            int     `json:"summoner_spell1_id" db:"summoner_spell1_id"`
+	SummonerSpell2ID            int     `json:"summoner_spell2_id" db:"summoner_spell2_id"`
+
+	// Joined by the recommendation queries
+	Items   sql.NullString `json:"items" db:"items"`
+	Style   sql.NullInt64  `json:"style" db:"style"`
+	Perk    sql.NullInt64  `json:"perk" db:"perk"`
+	Var1    sql.NullInt64  `json:"var1" db:"var1"`
+	Var2    sql.NullInt64  `json:"var2" db:"var2"`
+	Var3    sql.NullInt64  `json:"var3" db:"var3"`
+	Defense sql.NullInt64  `json:"defense" db:"defense"`
+	Flex    sql.NullInt64  `json:"flex" db:"flex"`
+	Offense sql.NullInt64  `json:"offense" db:"offense"`
+}
+
+// MatchUID returns the ID of the match of the participant, e.g. EUW1_123.
+func (p *Participant) MatchUID() string {
+	return fmt.Sprintf("%s_%d", p.PlatformID, p.MatchID)
 }
@@ -10,7 +10,7 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-const DSN = "root:password@tcp(127.0.0.1:3306)/lol-item-recommender"
+const DSN = "root:password@tcp(127.0.0.1:3306)/lol-item-recommender?parseTime=true"
 
 type DB struct {
 	db *sqlx.DB
@@ -19,7 +19,7 @@ type DB struct {
 func (d *DB) createTableMatches() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS matches (
-		    id BIGINT PRIMARY KEY,
+		    id BIGINT NOT NULL,
 		    match_uid VARCHAR(255) NOT NULL,
 		    creation BIGINT NOT NULL,
 		    duration INT NOT NULL,
@@ -33,7 +33,8 @@ func (d *DB) createTableMatches() error {
 		    platform_id VARCHAR(255) NOT NULL,
 		    queue_id INT NOT NULL,
 			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		    PRIMARY KEY (platform_id, id)
 		);
 	`
 
@@ -49,11 +50,16 @@ func (d *DB) createTableParticipants() error {
 		CREATE TABLE IF NOT EXISTS participants (
 		    participant_id INT NOT NULL,
 		    match_id BIGINT NOT NULL,
+		    platform_id VARCHAR(16) NOT NULL,
 		    summoner_id VARCHAR(255) NOT NULL,
 		    champion_id INT NOT NULL,
 		    team_id INT NOT NULL,
 		    role VARCHAR(255) NOT NULL,
 		    lane VARCHAR(255) NOT NULL,
+		    team_position VARCHAR(16) NOT NULL DEFAULT '',
+		    individual_position VARCHAR(16) NOT NULL DEFAULT '',
+		    inferred_position VARCHAR(16) NOT NULL DEFAULT '',
+		    position_confidence DOUBLE NOT NULL DEFAULT 0,
 		    kills INT NOT NULL,
 		    deaths INT NOT NULL,
 		    assists INT NOT NULL,
@@ -65,8 +71,8 @@ func (d *DB) createTableParticipants() error {
 		    summoner_spell2_id INT NOT NULL,
 			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-		    PRIMARY KEY (participant_id, match_id),
-		    FOREIGN KEY (match_id) REFERENCES matches(id),
+		    PRIMARY KEY (platform_id, match_id, participant_id),
+		    FOREIGN KEY (platform_id, match_id) REFERENCES matches(platform_id, id),
 		    FOREIGN KEY (summoner_id) REFERENCES summoners(id)
 		);
 	`
@@ -75,6 +81,36 @@ func (d *DB) createTableParticipants() error {
 	if err != nil {
 		return err
 	}
+	columns := [][2]string{
+		{"team_position", "VARCHAR(16) NOT NULL DEFAULT '' AFTER lane"},
+		{"individual_position", "VARCHAR(16) NOT NULL DEFAULT '' AFTER team_position"},
+		{"inferred_position", "VARCHAR(16) NOT NULL DEFAULT '' AFTER individual_position"},
+		{"position_confidence", "DOUBLE NOT NULL DEFAULT 0 AFTER inferred_position"},
+	}
+	for _, c := range columns {
+		if err := d.addColumnIfMissing("participants", c[0], c[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing adds the column to a table created by an older version.
+func (d *DB) addColumnIfMissing(table, column, definition string) error {
+	var count int
+	err := d.db.Get(&count, `
+		SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?`, table, column)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("can't add column %s.%s: %w", table, column, err)
+	}
 	return nil
 }
 
@@ -82,13 +118,14 @@ func (d *DB) createTableItems() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS items (
 		    match_id BIGINT NOT NULL,
+		    platform_id VARCHAR(16) NOT NULL,
 		    participant_id INT NOT NULL,
 		    item_id INT NOT NULL,
 		    slot INT NOT NULL,
 			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-		    PRIMARY KEY (participant_id, match_id, item_id),
-		    FOREIGN KEY (match_id) REFERENCES matches(id)
+		    PRIMARY KEY (platform_id, match_id, participant_id, item_id),
+		    FOREIGN KEY (platform_id, match_id) REFERENCES matches(platform_id, id)
 		);
 	`
 
@@ -122,14 +159,15 @@ func (d *DB) createTablePerks() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS perks (
 		    match_id BIGINT NOT NULL,
+		    platform_id VARCHAR(16) NOT NULL,
 		    participant_id INT NOT NULL,
 		    style INT NOT NULL,
 		    perk INT NOT NULL,
 		    var1 INT NOT NULL,
 		    var2 INT NOT NULL,
 		    var3 INT NOT NULL,
-		    PRIMARY KEY (participant_id, match_id),
-		    FOREIGN KEY (match_id) REFERENCES matches(id)
+		    PRIMARY KEY (platform_id, match_id, participant_id),
+		    FOREIGN KEY (platform_id, match_id) REFERENCES matches(platform_id, id)
 		);
 	`
 
@@ -145,12 +183,92 @@ func (d *DB) createTableStatPerks() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS stat_perks (
 		    match_id BIGINT NOT NULL,
+		    platform_id VARCHAR(16) NOT NULL,
 		    participant_id INT NOT NULL,
 		    defense INT NOT NULL,
 		    flex INT NOT NULL,
 		    offense INT NOT NULL,
-		    PRIMARY KEY (participant_id, match_id),
-		    FOREIGN KEY (match_id) REFERENCES matches(id)
+		    PRIMARY KEY (platform_id, match_id, participant_id),
+		    FOREIGN KEY (platform_id, match_id) REFERENCES matches(platform_id, id)
+		);
+	`
+
+	_, err := d.db.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// matchChildren are the tables of the match details, with their primary key.
+var matchChildren = [][2]string{
+	{"participants", "platform_id, match_id, participant_id"},
+	{"items", "platform_id, match_id, participant_id, item_id"},
+	{"perks", "platform_id, match_id, participant_id"},
+	{"stat_perks", "platform_id, match_id, participant_id"},
+}
+
+// primaryKeyHas reports whether the column is part of the primary key of the
+// table.
+func (d *DB) primaryKeyHas(table, column string) (bool, error) {
+	var count int
+	err := d.db.Get(&count, `
+		SELECT COUNT(*) FROM information_schema.key_column_usage
+		WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ? AND constraint_name = 'PRIMARY'`, table, column)
+	return count > 0, err
+}
+
+// migrateMatchKeys keys the matches created by an older version on their
+// platform too, the game IDs being unique within a platform only.
+func (d *DB) migrateMatchKeys() error {
+	migrated, err := d.primaryKeyHas("matches", "platform_id")
+	if err != nil || migrated {
+		return err
+	}
+	for _, c := range matchChildren {
+		var constraints []string
+		err := d.db.Select(&constraints, `
+			SELECT constraint_name FROM information_schema.referential_constraints
+			WHERE constraint_schema = DATABASE() AND table_name = ? AND referenced_table_name = 'matches'`, c[0])
+		if err != nil {
+			return err
+		}
+		for _, name := range constraints {
+			if _, err := d.db.Exec(fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", c[0], name)); err != nil {
+				return fmt.Errorf("can't drop foreign key of %s: %w", c[0], err)
+			}
+		}
+		if err := d.addColumnIfMissing(c[0], "platform_id", "VARCHAR(16) NOT NULL DEFAULT '' AFTER match_id"); err != nil {
+			return err
+		}
+		_, err = d.db.Exec(fmt.Sprintf(`
+			UPDATE %s c JOIN matches m ON m.id = c.match_id
+			SET c.platform_id = m.platform_id`, c[0]))
+		if err != nil {
+			return fmt.Errorf("can't set platform of %s: %w", c[0], err)
+		}
+	}
+	if _, err := d.db.Exec(`ALTER TABLE matches DROP PRIMARY KEY, ADD PRIMARY KEY (platform_id, id)`); err != nil {
+		return fmt.Errorf("can't change primary key of matches: %w", err)
+	}
+	for _, c := range matchChildren {
+		_, err := d.db.Exec(fmt.Sprintf(`
+			ALTER TABLE %s DROP PRIMARY KEY, ADD PRIMARY KEY (%s),
+			ADD FOREIGN KEY (platform_id, match_id) REFERENCES matches(platform_id, id)`, c[0], c[1]))
+		if err != nil {
+			return fmt.Errorf("can't change primary key of %s: %w", c[0], err)
+		}
+	}
+	return nil
+}
+
+func (d *DB) createTablePatches() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS patches (
+		    version VARCHAR(255) PRIMARY KEY,
+		    previous_version VARCHAR(255) NOT NULL,
+			detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
 	`
 
@@ -170,6 +288,13 @@ func (d *DB) CreateTables() error {
 		d.createTableItems,
 		d.createTablePerks,
 		d.createTableStatPerks,
+		d.migrateMatchKeys,
+		d.createTablePatches,
+		d.createTableCrawlFrontier,
+		d.createTableCrawlVisited,
+		d.createTableProcessedMatches,
+		d.createTableFailedMatches,
+		d.createTableTrackedPlayers,
 	}
 	for _, f := range fncs {
 		if err := f(); err != nil {
@@ -179,34 +304,19 @@ func (d *DB) CreateTables() error {
 	return nil
 }
 
-func (d *DB) saveItems(participant *gamedata.Participant, gameID int64) error {
+func (d *DB) saveItems(participant *gamedata.Participant, platformID string, gameID int64) error {
 	// Save items information
-	for i := 0; i < 7; i++ {
-		item := 0
-		switch i {
-		case 0:
-			item = participant.Item0
-		case 1:
-			item = participant.Item1
-		case 2:
-			item = participant.Item2
-		case 3:
-			item = participant.Item3
-		case 4:
-			item = participant.Item4
-		case 5:
-			item = participant.Item5
-		case 6:
-			item = participant.Item6
-		}
+	for i, item := range participant.Items() {
 		if item == 0 {
 			continue
 		}
 
 		_, err := d.db.Exec(`
-				INSERT INTO items (match_id, participant_id, slot, item_id)
-				VALUES (?, ?, ?, ?)`,
-			gameID, participant.ParticipantId, i, item)
+				INSERT INTO items (match_id, platform_id, participant_id, slot, item_id)
+				VALUES (?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					slot=VALUES(slot)`,
+			gameID, platformID, participant.ParticipantId, i, item)
 
 		if err != nil {
 			return fmt.Errorf("can't save item: %w", err)
@@ -215,19 +325,19 @@ func (d *DB) saveItems(participant *gamedata.Participant, gameID int64) error {
 	return nil
 }
 
-func (d *DB) savePerks(participant *gamedata.Participant, gameID int64) error {
+func (d *DB) savePerks(participant *gamedata.Participant, platformID string, gameID int64) error {
 	// Save perks information
 	for _, style := range participant.Perks.Styles {
 		for _, selection := range style.Selections {
 			_, err := d.db.Exec(`
-					INSERT INTO perks (match_id, participant_id, style, perk, var1, var2, var3)
-					VALUES (?, ?, ?, ?, ?, ?, ?)
+					INSERT INTO perks (match_id, platform_id, participant_id, style, perk, var1, var2, var3)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 					ON DUPLICATE KEY UPDATE
 						perk=VALUES(perk),
 						var1=VALUES(var1),
 						var2=VALUES(var2),
 						var3=VALUES(var3)`,
-				gameID, participant.ParticipantId, style.Style, selection.Perk, selection.Var1, selection.Var2, selection.Var3)
+				gameID, platformID, participant.ParticipantId, style.Style, selection.Perk, selection.Var1, selection.Var2, selection.Var3)
 
 			if err != nil {
 				return errors.Join(errors.New("can't insert participant style"), err)
@@ -238,13 +348,13 @@ func (d *DB) savePerks(participant *gamedata.Participant, gameID int64) error {
 	// Save stat perks information
 	statPerks := participant.Perks.StatPerks
 	_, err := d.db.Exec(`
-			INSERT INTO stat_perks (match_id, participant_id, offense, defense, flex)
-			VALUES (?, ?, ?, ?, ?)
+			INSERT INTO stat_perks (match_id, platform_id, participant_id, offense, defense, flex)
+			VALUES (?, ?, ?, ?, ?, ?)
 			ON DUPLICATE KEY UPDATE
 				offense=VALUES(offense),
 				defense=VALUES(defense),
 				flex=VALUES(flex)`,
-		gameID, participant.ParticipantId, statPerks.Offense, statPerks.Defense, statPerks.Flex)
+		gameID, platformID, participant.ParticipantId, statPerks.Offense, statPerks.Defense, statPerks.Flex)
 	return err
 }
 
@@ -266,15 +376,39 @@ func (d *DB) saveParticipants(match *gamedata.MatchData) error {
 		}
 
 		_, err = d.db.Exec(`
-			INSERT INTO participants (match_id, participant_id, summoner_id, champion_id, team_id, role, lane, kills, deaths, assists, champ_level, total_damage_dealt_to_champions, gold_earned, win, summoner_spell1_id, summoner_spell2_id)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			INSERT INTO participants (match_id, platform_id, participant_id, summoner_id, champion_id, team_id, role, lane, team_position, individual_position, inferred_position, position_confidence, kills, deaths, assists, champ_level, total_damage_dealt_to_champions, gold_earned, win, summoner_spell1_id, summoner_spell2_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				summoner_id=VALUES(summoner_id),
+				champion_id=VALUES(champion_id),
+				team_id=VALUES(team_id),
+				role=VALUES(role),
+				lane=VALUES(lane),
+				team_position=VALUES(team_position),
+				individual_position=VALUES(individual_position),
+				inferred_position=VALUES(inferred_position),
+				position_confidence=VALUES(position_confidence),
+				kills=VALUES(kills),
+				deaths=VALUES(deaths),
+				assists=VALUES(assists),
+				champ_level=VALUES(champ_level),
+				total_damage_dealt_to_champions=VALUES(total_damage_dealt_to_champions),
+				gold_earned=VALUES(gold_earned),
+				win=VALUES(win),
+				summoner_spell1_id=VALUES(summoner_spell1_id),
+				summoner_spell2_id=VALUES(summoner_spell2_id)`,
 			match.Info.GameId,
+			match.Info.PlatformId,
 			participant.ParticipantId,
 			participant.SummonerId,
 			participant.ChampionId,
 			participant.TeamId,
 			participant.Role,
 			participant.Lane,
+			participant.TeamPosition,
+			participant.IndividualPosition,
+			participant.InferredPosition,
+			participant.PositionConfidence,
 			participant.Kills,
 			participant.Deaths,
 			participant.Assists,
@@ -289,22 +423,38 @@ func (d *DB) saveParticipants(match *gamedata.MatchData) error {
 			return fmt.Errorf("can't insert participant: %w", err)
 		}
 
-		if err := d.saveItems(&participant, match.Info.GameId); err != nil {
+		if err := d.saveItems(&participant, match.Info.PlatformId, match.Info.GameId); err != nil {
 			return fmt.Errorf("can't save items: %w", err)
 		}
 
-		if err := d.savePerks(&participant, match.Info.GameId); err != nil {
+		if err := d.savePerks(&participant, match.Info.PlatformId, match.Info.GameId); err != nil {
 			return fmt.Errorf("can't insert perks: %w", err)
 		}
 	}
 	return nil
 }
 
+// SaveMatch saves the match and its participants. Saving a match twice
+// overwrites the participants of the first copy, so matches can be
+// reprocessed to fill new columns. Matches are keyed by platform and game ID,
+// the game IDs of two platforms colliding.
 func (d *DB) SaveMatch(match *gamedata.MatchData) error {
 	// Save match information
 	_, err := d.db.Exec(`
-		INSERT IGNORE INTO matches (id, match_uid, creation, duration, end_timestamp, mode, name, start_timestamp, type, version, map_id, platform_id, queue_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		INSERT INTO matches (id, match_uid, creation, duration, end_timestamp, mode, name, start_timestamp, type, version, map_id, platform_id, queue_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			match_uid=VALUES(match_uid),
+			creation=VALUES(creation),
+			duration=VALUES(duration),
+			end_timestamp=VALUES(end_timestamp),
+			mode=VALUES(mode),
+			name=VALUES(name),
+			start_timestamp=VALUES(start_timestamp),
+			type=VALUES(type),
+			version=VALUES(version),
+			map_id=VALUES(map_id),
+			queue_id=VALUES(queue_id)`,
 		match.Info.GameId, match.Metadata.MatchId, match.Info.GameCreation, match.Info.GameDuration, match.Info.GameEndTimestamp, match.Info.GameMode, match.Info.GameName, match.Info.GameStartTimestamp, match.Info.GameType, match.Info.GameVersion, match.Info.MapId, match.Info.PlatformId, match.Info.QueueId)
 
 	if err != nil {
@@ -313,29 +463,13 @@ func (d *DB) SaveMatch(match *gamedata.MatchData) error {
 	return d.saveParticipants(match)
 }
 
-func (d *DB) GetLatestCrawledPlayers() ([]*gamedata.Player, error) {
-	var p []*gamedata.Player
-	err := d.db.Select(&p, `select id, name, level from summoners order by created_at limit 200`)
+// SavePatchBoundary records the moment a new patch was detected.
+func (d *DB) SavePatchBoundary(previous, current string) error {
+	_, err := d.db.Exec(`INSERT IGNORE INTO patches (version, previous_version) VALUES (?, ?)`, current, previous)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("can't save patch boundary: %w", err)
 	}
-	return p, nil
-}
-
-func (d *DB) GetAllPlayerNameCrawledExcept(exceptions []string) ([]string, error) {
-	var pName []string
-	query, args, err := sqlx.In(`SELECT name FROM summoners WHERE name NOT IN (?)`, exceptions)
-	if err != nil {
-		return nil, err
-	}
-
-	query = d.db.Rebind(query)
-	err = d.db.Select(&pName, query, args...)
-
-	if err != nil {
-		return nil, err
-	}
-	return pName, nil
+	return nil
 }
 
 //func (d *DB) AssociateItemToParticipants(participants []*gamedata.Participant) {
@@ -344,7 +478,9 @@ func (d *DB) GetAllPlayerNameCrawledExcept(exceptions []string) ([]string, error
 //	for participants
 //}
 
-func (d *DB) GetMatchesWithChampions(blueTeam, redTeam []*gamedata.ChampionStats) (map[int][]*gamedata.Participant, error) {
+// GetMatchesWithChampions returns, per match UID, the participants playing
+// the target champion in the matches opposing the blue team to the red team.
+func (d *DB) GetMatchesWithChampions(target *gamedata.ChampionStats, blueTeam, redTeam []*gamedata.ChampionStats) (map[string][]*Participant, error) {
 	// Prepare the SQL query
 	allChamps := make([]string, 0)
 	lenTeam1 := len(blueTeam)
@@ -376,11 +512,16 @@ func (d *DB) GetMatchesWithChampions(blueTeam, redTeam []*gamedata.ChampionStats
 	query := `
 		SELECT p.participant_id,
 			   p.match_id,
+			   p.platform_id,
 			   p.summoner_id,
 			   p.champion_id,
 			   p.team_id,
 			   p.role,
 			   p.lane,
+			   p.team_position,
+			   p.individual_position,
+			   p.inferred_position,
+			   p.position_confidence,
 			   p.kills,
 			   p.deaths,
 			   p.assists,
@@ -400,25 +541,27 @@ func (d *DB) GetMatchesWithChampions(blueTeam, redTeam []*gamedata.ChampionStats
 			   sp.flex,
 			   sp.offense
 		FROM participants p
-				LEFT JOIN items i ON i.match_id = p.match_id AND i.participant_id = p.participant_id
-				LEFT JOIN perks pe ON p.match_id = pe.match_id AND p.participant_id = pe.participant_id
-				LEFT JOIN stat_perks sp ON p.match_id = sp.match_id AND p.participant_id = sp.participant_id
-		WHERE p.match_id IN (
-			SELECT match_id
+				LEFT JOIN items i ON i.platform_id = p.platform_id AND i.match_id = p.match_id AND i.participant_id = p.participant_id
+				LEFT JOIN perks pe ON p.platform_id = pe.platform_id AND p.match_id = pe.match_id AND p.participant_id = pe.participant_id
+				LEFT JOIN stat_perks sp ON p.platform_id = sp.platform_id AND p.match_id = sp.match_id AND p.participant_id = sp.participant_id
+		WHERE p.champion_id = %s AND (p.platform_id, p.match_id) IN (
+			SELECT platform_id, match_id
 			FROM (
-				SELECT match_id,
+				SELECT platform_id,
+					   match_id,
 					   %s AS team1_champs_A,
 					   %s AS team2_champs_A,
 					   %s AS team1_champs_B,
 					   %s AS team2_champs_B
 				FROM participants
 				WHERE champion_id IN (%s)
-				GROUP BY match_id
+				GROUP BY platform_id, match_id
 			) as subquery
 			WHERE (team1_champs_A = %d AND team2_champs_B = %d) OR (team2_champs_A = %d AND team1_champs_B = %d)
-		);
+		)
+		GROUP BY p.platform_id, p.match_id, p.participant_id;
     `
-	query = fmt.Sprintf(query, sumTeam1ChampsA, sumTeam1ChampsB, sumTeam2ChampsA, sumTeam2ChampsB, strings.Join(allChamps, ","), lenTeam1, lenTeam2, lenTeam1, lenTeam2)
+	query = fmt.Sprintf(query, target.Key, sumTeam1ChampsA, sumTeam1ChampsB, sumTeam2ChampsA, sumTeam2ChampsB, strings.Join(allChamps, ","), lenTeam1, lenTeam2, lenTeam1, lenTeam2)
 
 	var participants []*Participant
 	// Execute the SQL query
@@ -426,10 +569,18 @@ func (d *DB) GetMatchesWithChampions(blueTeam, redTeam []*gamedata.ChampionStats
 	if err != nil {
 		return nil, err
 	}
-	participantsPerMatch := make(map[int][]*gamedata.Participant)
+	participantsPerMatch := make(map[string][]*Participant)
+	for _, p := range participants {
+		uid := p.MatchUID()
+		participantsPerMatch[uid] = append(participantsPerMatch[uid], p)
+	}
 	return participantsPerMatch, nil
 }
 
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
 func NewDB() (*DB, error) {
 	db, err := sqlx.Connect("mysql", DSN)
 	if err != nil {
@@ -0,0 +1,56 @@
+package gold
+
+import (
+	"math"
+	"sort"
+)
+
+// Change is the efficiency evolution of an item between two patches.
+type Change struct {
+	ID     string
+	Name   string
+	Before *Efficiency
+	After  *Efficiency
+	// Efficiency difference in percentage points.
+	Delta float64
+}
+
+// Compare returns the items whose efficiency changed between the two analyses,
+// the biggest changes first. Items added or removed are included with a nil
+// Before or After.
+func Compare(before, after *Analysis) []*Change {
+	changes := make([]*Change, 0)
+	for id, a := range after.Items {
+		c := &Change{ID: id, Name: a.Item.Name, After: a, Delta: a.Percent}
+		if b, ok := before.Items[id]; ok {
+			c.Before = b
+			c.Delta = a.Percent - b.Percent
+		}
+		changes = append(changes, c)
+	}
+	for id, b := range before.Items {
+		if _, ok := after.Items[id]; !ok {
+			changes = append(changes, &Change{ID: id, Name: b.Item.Name, Before: b, Delta: -b.Percent})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		di, dj := math.Abs(changes[i].Delta), math.Abs(changes[j].Delta)
+		if di != dj {
+			return di > dj
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// Significant returns the changes whose delta is at least the given number
+// of percentage points.
+func Significant(changes []*Change, threshold float64) []*Change {
+	s := make([]*Change, 0)
+	for _, c := range changes {
+		if math.Abs(c.Delta) >= threshold {
+			s = append(s, c)
+		}
+	}
+	return s
+}
@@ -0,0 +1,183 @@
+package gold
+
+import (
+	"sort"
+
+	"LoLItemRecommender/internal/calculator"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+type Stat string
+
+const (
+	StatHP               Stat = "Health"
+	StatMana             Stat = "Mana"
+	StatHPRegen          Stat = "Base Health Regen"
+	StatManaRegen        Stat = "Base Mana Regen"
+	StatArmor            Stat = "Armor"
+	StatMagicResist      Stat = "Magic Resist"
+	StatAttackDamage     Stat = "Attack Damage"
+	StatAbilityPower     Stat = "Ability Power"
+	StatAttackSpeed      Stat = "Attack Speed"
+	StatCritChance       Stat = "Critical Strike Chance"
+	StatLifeSteal        Stat = "Life Steal"
+	StatMoveSpeed        Stat = "Movement Speed"
+	StatMoveSpeedPercent Stat = "Movement Speed %"
+	StatLethality        Stat = "Lethality"
+	StatArmorPen         Stat = "Armor Penetration"
+	StatMagicPen         Stat = "Magic Penetration"
+	StatMagicPenPercent  Stat = "Magic Penetration %"
+	StatAbilityHaste     Stat = "Ability Haste"
+	StatOmnivamp         Stat = "Omnivamp"
+)
+
+// Stats returns the non-zero stats granted by the item.
+func Stats(item *gamedata.ItemData) map[Stat]float64 {
+	is := item.Stats
+	ds := calculator.ParseDescriptionStats(item.Description)
+	all := map[Stat]float64{
+		StatHP:               is.FlatHPPoolMod,
+		StatMana:             is.FlatMPPoolMod,
+		StatHPRegen:          is.PercentHPRegenMod,
+		StatManaRegen:        is.PercentMPRegenMod,
+		StatArmor:            is.FlatArmorMod,
+		StatMagicResist:      is.FlatSpellBlockMod,
+		StatAttackDamage:     is.FlatPhysicalDamageMod,
+		StatAbilityPower:     is.FlatMagicDamageMod,
+		StatAttackSpeed:      is.PercentAttackSpeedMod,
+		StatCritChance:       is.FlatCritChanceMod,
+		StatLifeSteal:        is.PercentLifeStealMod,
+		StatMoveSpeed:        is.FlatMovementSpeedMod,
+		StatMoveSpeedPercent: is.PercentMovementSpeedMod,
+		StatLethality:        ds.Lethality,
+		StatArmorPen:         ds.ArmorPenetration,
+		StatMagicPen:         ds.MagicPenetration,
+		StatMagicPenPercent:  ds.MagicPenPercent,
+		StatAbilityHaste:     ds.AbilityHaste,
+		StatOmnivamp:         ds.Omnivamp,
+	}
+	for s, v := range all {
+		if v == 0 {
+			delete(all, s)
+		}
+	}
+	return all
+}
+
+// Efficiency is the gold value of an item compared to its cost.
+type Efficiency struct {
+	ID    string
+	Item  *gamedata.ItemData
+	Value float64
+	// Value divided by the total cost, in percent.
+	Percent float64
+	// Stats of the item with no reference gold value, not counted in Value.
+	Unvalued []Stat
+}
+
+// Analysis holds the reference gold value of each stat and the efficiency of
+// every purchasable Summoner's Rift item of a patch.
+type Analysis struct {
+	Version   string
+	Reference map[Stat]float64
+	Items     map[string]*Efficiency
+}
+
+// ReferenceValues derives the gold value of one point of each stat. Basic
+// components granting a single stat give the first values, then components
+// with exactly one stat left unknown are used to derive the remaining ones.
+func ReferenceValues(items map[string]*gamedata.ItemData) map[Stat]float64 {
+	ref := make(map[Stat]float64)
+	components := make([]string, 0)
+	for id, item := range items {
+		if item.IsPurchasable(gamedata.SummonersRiftMapID) && len(item.Into) > 0 {
+			components = append(components, id)
+		}
+	}
+	// Cheapest first, so the basic components set the reference of a stat.
+	sort.Slice(components, func(i, j int) bool {
+		a, b := items[components[i]], items[components[j]]
+		if len(a.From) != len(b.From) {
+			return len(a.From) < len(b.From)
+		}
+		if a.Gold.Total != b.Gold.Total {
+			return a.Gold.Total < b.Gold.Total
+		}
+		return components[i] < components[j]
+	})
+
+	for changed := true; changed; {
+		changed = false
+		for _, id := range components {
+			item := items[id]
+			var (
+				unknown      Stat
+				unknownCount int
+				known        float64
+			)
+			stats := Stats(item)
+			for s, v := range stats {
+				if g, ok := ref[s]; ok {
+					known += g * v
+				} else {
+					unknown = s
+					unknownCount++
+				}
+			}
+			if unknownCount != 1 {
+				continue
+			}
+			remaining := float64(item.Gold.Total) - known
+			if remaining <= 0 {
+				continue
+			}
+			ref[unknown] = remaining / stats[unknown]
+			changed = true
+		}
+	}
+	return ref
+}
+
+// Analyze computes the gold efficiency of every purchasable item.
+func Analyze(version string, items map[string]*gamedata.ItemData) *Analysis {
+	a := &Analysis{
+		Version:   version,
+		Reference: ReferenceValues(items),
+		Items:     make(map[string]*Efficiency),
+	}
+	for id, item := range items {
+		if !item.IsPurchasable(gamedata.SummonersRiftMapID) {
+			continue
+		}
+		e := &Efficiency{ID: id, Item: item}
+		for s, v := range Stats(item) {
+			if g, ok := a.Reference[s]; ok {
+				e.Value += g * v
+			} else {
+				e.Unvalued = append(e.Unvalued, s)
+			}
+		}
+		if e.Value == 0 {
+			continue
+		}
+		sort.Slice(e.Unvalued, func(i, j int) bool { return e.Unvalued[i] < e.Unvalued[j] })
+		e.Percent = e.Value / float64(item.Gold.Total) * 100
+		a.Items[id] = e
+	}
+	return a
+}
+
+// Sorted returns the items sorted by decreasing efficiency.
+func (a *Analysis) Sorted() []*Efficiency {
+	s := make([]*Efficiency, 0, len(a.Items))
+	for _, e := range a.Items {
+		s = append(s, e)
+	}
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].Percent != s[j].Percent {
+			return s[i].Percent > s[j].Percent
+		}
+		return s[i].ID < s[j].ID
+	})
+	return s
+}
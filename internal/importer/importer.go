@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"LoLItemRecommender/internal/crawler"
+	"LoLItemRecommender/internal/database"
+	"LoLItemRecommender/internal/positions"
+	"LoLItemRecommender/internal/printer"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// Report counts the outcome of an import.
+type Report struct {
+	mu       sync.Mutex
+	start    time.Time
+	files    int
+	saved    int
+	rejected map[string]int
+	skipped  map[string]error
+}
+
+func newReport() *Report {
+	return &Report{start: time.Now(), rejected: make(map[string]int), skipped: make(map[string]error)}
+}
+
+// Skip records a file which couldn't be imported.
+func (r *Report) Skip(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skipped[name] = err
+	printer.Warn("Skipping %s: %v", name, err)
+}
+
+func (r *Report) reject(name, matchID, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rejected[reason]++
+	printer.Debug("Rejected %s from %s: %s", matchID, name, reason)
+}
+
+func (r *Report) progress() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rejected := 0
+	for _, n := range r.rejected {
+		rejected += n
+	}
+	rate := float64(r.saved+rejected) / time.Since(r.start).Seconds()
+	printer.Info("{-F_MAGENTA,BOLD}%d {-RESET}files read, {-F_GREEN,BOLD}%d {-RESET}matches saved, {-F_YELLOW}%d {-RESET}rejected, {-F_RED}%d {-RESET}files skipped (%.1f matches/s)",
+		r.files, r.saved, rejected, len(r.skipped), rate)
+}
+
+// Print prints the totals, the rejection reasons and the skipped files.
+func (r *Report) Print() {
+	r.progress()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reasons := make([]string, 0, len(r.rejected))
+	for reason := range r.rejected {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		printer.Info("  Rejected, %s: {-F_YELLOW}%d", reason, r.rejected[reason])
+	}
+	names := make([]string, 0, len(r.skipped))
+	for name := range r.skipped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		printer.Info("  Skipped {-F_RED}%s{-RESET}: %v", name, r.skipped[name])
+	}
+}
+
+// Importer saves the matches of payloads with the crawler filters, without
+// any call to the Riot API.
+type Importer struct {
+	db       *database.DB
+	sd       *gamedata.StaticData
+	filter   *crawler.Filter
+	Workers  int
+	Interval time.Duration
+}
+
+func New(db *database.DB, sd *gamedata.StaticData, f *crawler.Filter) *Importer {
+	return &Importer{db: db, sd: sd, filter: f, Workers: 1, Interval: 5 * time.Second}
+}
+
+func (imp *Importer) importPayloads(in <-chan Payload, r *Report) {
+	for p := range in {
+		matches, err := Decode(p)
+		r.mu.Lock()
+		r.files++
+		r.mu.Unlock()
+		if err != nil {
+			r.Skip(p.Name, err)
+			continue
+		}
+		for _, m := range matches {
+			positions.Complete(m, imp.sd)
+			reason := imp.filter.RejectReason(m)
+			keep := reason == ""
+			if keep {
+				if err := imp.db.SaveMatch(m); err != nil {
+					r.Skip(p.Name, fmt.Errorf("match %s: %w", m.Metadata.MatchId, err))
+					continue
+				}
+				r.mu.Lock()
+				r.saved++
+				r.mu.Unlock()
+			} else {
+				r.reject(p.Name, m.Metadata.MatchId, reason)
+			}
+			// The crawler won't download the imported matches again
+			if err := imp.db.MarkMatchProcessed(strings.ToLower(m.Info.PlatformId), m.Metadata.MatchId, keep); err != nil {
+				printer.Error("Unable to mark %s as processed: %v", m.Metadata.MatchId, err)
+			}
+		}
+	}
+}
+
+// Run imports the payloads sent by produce with parallel workers, reporting
+// the progress at each interval, and returns the report once every payload
+// is imported.
+func (imp *Importer) Run(produce func(out chan<- Payload, r *Report)) *Report {
+	r := newReport()
+	payloads := make(chan Payload, imp.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < imp.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			imp.importPayloads(payloads, r)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(imp.Interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				r.progress()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	produce(payloads, r)
+	close(payloads)
+	wg.Wait()
+	close(done)
+	return r
+}
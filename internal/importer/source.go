@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+var (
+	ErrNotAMatch       = errors.New("not a match-v5 payload")
+	ErrUnsupportedFile = errors.New("not a JSON file")
+)
+
+// Payload is the content of a file, possibly gzipped, holding one or more
+// matches.
+type Payload struct {
+	Name string
+	Data []byte
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func isTarball(name string) bool {
+	return strings.HasSuffix(name, ".tar") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+func isMatchFile(name string) bool {
+	name = strings.TrimSuffix(name, ".gz")
+	return strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".ndjson") || strings.HasSuffix(name, ".jsonl")
+}
+
+// maybeGunzip returns a reader of the uncompressed content of r, which is
+// read as is if it isn't gzipped.
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
+
+// Walk sends the match files found in path, a file, a directory or a tarball,
+// to out. The files which can't be read are given to skip.
+func Walk(path string, out chan<- Payload, skip func(name string, err error)) error {
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return nil
+		case isTarball(p):
+			if err := walkTarball(p, out, skip); err != nil {
+				skip(p, err)
+			}
+		case isMatchFile(p):
+			data, err := os.ReadFile(p)
+			if err != nil {
+				skip(p, err)
+				return nil
+			}
+			out <- Payload{Name: p, Data: data}
+		default:
+			skip(p, ErrUnsupportedFile)
+		}
+		return nil
+	})
+}
+
+func walkTarball(path string, out chan<- Payload, skip func(name string, err error)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := maybeGunzip(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path + ":" + h.Name
+		if !isMatchFile(h.Name) {
+			skip(name, ErrUnsupportedFile)
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		out <- Payload{Name: name, Data: data}
+	}
+}
+
+// Decode returns the matches of the payload: a match, an array of matches or
+// one match per line.
+func Decode(p Payload) ([]*gamedata.MatchData, error) {
+	r, err := maybeGunzip(bytes.NewReader(p.Data))
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(r)
+	matches := make([]*gamedata.MatchData, 0, 1)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		var batch []*gamedata.MatchData
+		if raw[0] == '[' {
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, err
+			}
+		} else {
+			m := new(gamedata.MatchData)
+			if err := json.Unmarshal(raw, m); err != nil {
+				return nil, err
+			}
+			batch = append(batch, m)
+		}
+		for _, m := range batch {
+			if m.Metadata.MatchId == "" || len(m.Info.Participants) == 0 {
+				return nil, fmt.Errorf("match %d: %w", len(matches)+1, ErrNotAMatch)
+			}
+		}
+		matches = append(matches, batch...)
+	}
+	if len(matches) == 0 {
+		return nil, ErrNotAMatch
+	}
+	return matches, nil
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+)
+
+const (
+	match1 = `{"metadata":{"matchId":"EUW1_1"},"info":{"participants":[{"championId":360}]}}`
+	match2 = `{"metadata":{"matchId":"EUW1_2"},"info":{"participants":[{"championId":222}]}}`
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+		err  error
+	}{
+		{"single match", []byte(match1), []string{"EUW1_1"}, nil},
+		{"array", []byte("[" + match1 + "," + match2 + "]"), []string{"EUW1_1", "EUW1_2"}, nil},
+		{"one per line", []byte(match1 + "\n" + match2 + "\n"), []string{"EUW1_1", "EUW1_2"}, nil},
+		{"gzipped", gzipped(t, match1+"\n"+match2), []string{"EUW1_1", "EUW1_2"}, nil},
+		{"empty", nil, nil, ErrNotAMatch},
+		{"not a match", []byte(`{"summonerId":"abc"}`), nil, ErrNotAMatch},
+		{"match without participants", []byte(`{"metadata":{"matchId":"EUW1_3"},"info":{}}`), nil, ErrNotAMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := Decode(Payload{Name: tt.name, Data: tt.data})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.err)
+			}
+			if len(matches) != len(tt.want) {
+				t.Fatalf("Decode() returned %d matches, want %d", len(matches), len(tt.want))
+			}
+			for i, m := range matches {
+				if m.Metadata.MatchId != tt.want[i] {
+					t.Errorf("match %d is %s, want %s", i, m.Metadata.MatchId, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeInvalidJSON(t *testing.T) {
+	if _, err := Decode(Payload{Data: []byte(match1 + "\n{")}); err == nil {
+		t.Error("Decode() = nil error for a truncated payload")
+	}
+}
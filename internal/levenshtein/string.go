@@ -1,12 +1,24 @@
 package levenshtein
 
 func StringSimilarity(s1, s2 string) float64 {
+	return similarity(s1, s2, levenshteinDistance)
+}
+
+// DamerauSimilarity works like StringSimilarity but counts the transposition
+// of two adjacent characters as a single edit.
+func DamerauSimilarity(s1, s2 string) float64 {
+	return similarity(s1, s2, DamerauDistance)
+}
+
+func similarity(s1, s2 string, distance func(r1, r2 []rune) int) float64 {
 	if s1 == s2 {
 		return 100.0
 	}
 
-	len1 := len(s1)
-	len2 := len(s2)
+	r1 := []rune(s1)
+	r2 := []rune(s2)
+	len1 := len(r1)
+	len2 := len(r2)
 	if len1 == 0 || len2 == 0 {
 		return 0.0
 	}
@@ -16,16 +28,11 @@ func StringSimilarity(s1, s2 string) float64 {
 		maxLength = len2
 	}
 
-	distance := levenshteinDistance(s1, s2)
-	similarity := 100.0 - (float64(distance) / float64(maxLength) * 100.0)
-	return similarity
+	d := distance(r1, r2)
+	return 100.0 - (float64(d) / float64(maxLength) * 100.0)
 }
 
-func levenshteinDistance(s1, s2 string) int {
-	len1 := len(s1)
-	len2 := len(s2)
-
-	// Créer la matrice de distances
+func newMatrix(len1, len2 int) [][]int {
 	matrix := make([][]int, len1+1)
 	for i := range matrix {
 		matrix[i] = make([]int, len2+1)
@@ -34,6 +41,26 @@ func levenshteinDistance(s1, s2 string) int {
 	for j := range matrix[0] {
 		matrix[0][j] = j
 	}
+	return matrix
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
+
+func levenshteinDistance(s1, s2 []rune) int {
+	len1 := len(s1)
+	len2 := len(s2)
+
+	// Créer la matrice de distances
+	matrix := newMatrix(len1, len2)
 
 	// Calculer les distances
 	for j := 1; j <= len2; j++ {
@@ -41,14 +68,7 @@ func levenshteinDistance(s1, s2 string) int {
 			if s1[i-1] == s2[j-1] {
 				matrix[i][j] = matrix[i-1][j-1]
 			} else {
-				min := matrix[i-1][j]
-				if matrix[i][j-1] < min {
-					min = matrix[i][j-1]
-				}
-				if matrix[i-1][j-1] < min {
-					min = matrix[i-1][j-1]
-				}
-				matrix[i][j] = min + 1
+				matrix[i][j] = min3(matrix[i-1][j], matrix[i][j-1], matrix[i-1][j-1]) + 1
 			}
 		}
 	}
@@ -56,3 +76,26 @@ func levenshteinDistance(s1, s2 string) int {
 	// Retourner la distance entre les deux chaînes
 	return matrix[len1][len2]
 }
+
+// DamerauDistance returns the optimal string alignment distance between the
+// two strings: insertions, deletions, substitutions and transpositions of
+// adjacent runes all cost one edit.
+func DamerauDistance(s1, s2 []rune) int {
+	len1 := len(s1)
+	len2 := len(s2)
+	matrix := newMatrix(len1, len2)
+
+	for i := 1; i <= len1; i++ {
+		for j := 1; j <= len2; j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			matrix[i][j] = min3(matrix[i-1][j]+1, matrix[i][j-1]+1, matrix[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s1[i-1] == s2[j-2] && s1[i-2] == s2[j-1] && matrix[i-2][j-2]+1 < matrix[i][j] {
+				matrix[i][j] = matrix[i-2][j-2] + 1
+			}
+		}
+	}
+	return matrix[len1][len2]
+}
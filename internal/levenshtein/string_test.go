@@ -0,0 +1,49 @@
+package levenshtein
+
+import "testing"
+
+func TestDamerauDistance(t *testing.T) {
+	tests := []struct {
+		s1, s2 string
+		want   int
+	}{
+		{"", "", 0},
+		{"kaisa", "", 5},
+		{"", "kayn", 4},
+		{"kaisa", "kaisa", 0},
+		{"kiasa", "kaisa", 1},
+		{"kasadin", "kassadin", 1},
+		{"ahri", "ashe", 3},
+		// Optimal string alignment: a substring is never edited twice
+		{"ca", "abc", 3},
+		// Runes, not bytes
+		{"ézreal", "ezreal", 1},
+		{"ézreal", "zéreal", 1},
+	}
+	for _, tt := range tests {
+		if got := DamerauDistance([]rune(tt.s1), []rune(tt.s2)); got != tt.want {
+			t.Errorf("DamerauDistance(%q, %q) = %d, want %d", tt.s1, tt.s2, got, tt.want)
+		}
+		if got := DamerauDistance([]rune(tt.s2), []rune(tt.s1)); got != tt.want {
+			t.Errorf("DamerauDistance(%q, %q) = %d, want %d", tt.s2, tt.s1, got, tt.want)
+		}
+	}
+}
+
+func TestDamerauSimilarity(t *testing.T) {
+	tests := []struct {
+		s1, s2 string
+		want   float64
+	}{
+		{"", "", 100},
+		{"kaisa", "", 0},
+		{"kaisa", "kaisa", 100},
+		{"kiasa", "kaisa", 80},
+		{"abcd", "wxyz", 0},
+	}
+	for _, tt := range tests {
+		if got := DamerauSimilarity(tt.s1, tt.s2); got != tt.want {
+			t.Errorf("DamerauSimilarity(%q, %q) = %v, want %v", tt.s1, tt.s2, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package positions
+
+import (
+	"math"
+	"strconv"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+const (
+	teamSize = 5
+	smiteID  = 11
+)
+
+// Scores added to a position for each signal, they are summed then
+// converted to probabilities, so a difference of 1 is a factor e.
+const (
+	teamPositionScore       = 6.0
+	individualPositionScore = 3.0
+	legacyPositionScore     = 2.0
+	smiteScore              = 5.0
+	jungleCampsScore        = 3.0
+	supportItemScore        = 5.0
+	lowCSScore              = 2.0
+
+	// Below this number of minions per minute, the participant is likely to
+	// be the support.
+	lowCSPerMinute = 2.5
+	// Above this share of jungle monsters in the CS, the participant is
+	// likely to be the jungler.
+	jungleCampsRatio = 0.5
+)
+
+// Champion class score per position.
+var tagScores = map[string]map[gamedata.Position]float64{
+	"Marksman": {gamedata.PositionBottom: 2},
+	"Support":  {gamedata.PositionUtility: 1.5},
+	"Mage":     {gamedata.PositionMiddle: 1, gamedata.PositionUtility: 0.5},
+	"Assassin": {gamedata.PositionMiddle: 1, gamedata.PositionJungle: 0.5},
+	"Tank":     {gamedata.PositionTop: 1, gamedata.PositionJungle: 0.5, gamedata.PositionUtility: 0.5},
+	"Fighter":  {gamedata.PositionTop: 1.5, gamedata.PositionJungle: 1},
+}
+
+// Support quest items, they are bought by the supports only.
+var supportItems = map[int]bool{
+	3850: true, 3851: true, 3853: true, // Spellthief's Edge
+	3854: true, 3855: true, 3857: true, // Steel Shoulderguards
+	3858: true, 3859: true, 3860: true, // Relic Shield
+	3862: true, 3863: true, 3864: true, // Spectral Sickle
+	3865: true, 3866: true, 3867: true, // World Atlas
+	3869: true, 3870: true, 3871: true, 3876: true, 3877: true, // Bounty of Worlds upgrades
+}
+
+// Assignment is the position given to a participant with the probability
+// that it is the right one.
+type Assignment struct {
+	Position   gamedata.Position
+	Confidence float64
+}
+
+func hasSupportItem(p *gamedata.Participant, sd *gamedata.StaticData) bool {
+	for _, id := range p.Items() {
+		if supportItems[id] {
+			return true
+		}
+		// Support items of the patches not listed above share this tag
+		if item, ok := sd.ItemsData[strconv.Itoa(id)]; ok && hasTag(item.Tags, "GoldPer") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// gameMinutes returns the match duration in minutes. Before patch 11.20 the
+// duration was given in milliseconds and there was no end timestamp.
+func gameMinutes(match *gamedata.MatchData) float64 {
+	if match.Info.GameEndTimestamp == 0 {
+		return float64(match.Info.GameDuration) / 60000
+	}
+	return float64(match.Info.GameDuration) / 60
+}
+
+// scores returns the score of each position for the participant.
+func scores(p *gamedata.Participant, minutes float64, sd *gamedata.StaticData) map[gamedata.Position]float64 {
+	s := make(map[gamedata.Position]float64, len(gamedata.Positions))
+	if pos, ok := gamedata.ParsePosition(p.TeamPosition); ok {
+		s[pos] += teamPositionScore
+	}
+	if pos, ok := gamedata.ParsePosition(p.IndividualPosition); ok {
+		s[pos] += individualPositionScore
+	}
+	if pos := gamedata.LegacyPosition(p); pos != gamedata.PositionNone {
+		s[pos] += legacyPositionScore
+	}
+	if p.Summoner1Id == smiteID || p.Summoner2Id == smiteID {
+		s[gamedata.PositionJungle] += smiteScore
+	}
+	if cs := p.TotalMinionsKilled + p.NeutralMinionsKilled; cs > 0 && float64(p.NeutralMinionsKilled)/float64(cs) > jungleCampsRatio {
+		s[gamedata.PositionJungle] += jungleCampsScore
+	}
+	if hasSupportItem(p, sd) {
+		s[gamedata.PositionUtility] += supportItemScore
+	}
+	if minutes > 0 && float64(p.TotalMinionsKilled)/minutes < lowCSPerMinute {
+		s[gamedata.PositionUtility] += lowCSScore
+	}
+	if c := sd.GetChampionByID(p.ChampionId); c != nil {
+		for _, tag := range c.Tags {
+			for pos, v := range tagScores[tag] {
+				s[pos] += v
+			}
+		}
+	}
+	return s
+}
+
+// permutations calls f with every ordering of the positions.
+func permutations(positions []gamedata.Position, f func([]gamedata.Position)) {
+	var permute func(k int)
+	permute = func(k int) {
+		if k == len(positions) {
+			f(positions)
+			return
+		}
+		for i := k; i < len(positions); i++ {
+			positions[k], positions[i] = positions[i], positions[k]
+			permute(k + 1)
+			positions[k], positions[i] = positions[i], positions[k]
+		}
+	}
+	permute(0)
+}
+
+// inferTeam gives the five positions to the five participants of a team.
+// Every possible assignment is weighted by the exponential of its total
+// score: the chosen one is the heaviest, and the confidence of each
+// participant is the weight share of the assignments agreeing with it.
+func inferTeam(team []*gamedata.Participant, minutes float64, sd *gamedata.StaticData) []Assignment {
+	s := make([]map[gamedata.Position]float64, len(team))
+	for i, p := range team {
+		s[i] = scores(p, minutes, sd)
+	}
+
+	var (
+		bestTotal = math.Inf(-1)
+		best      = make([]gamedata.Position, teamSize)
+		totals    = make([]float64, 0)
+		perms     = make([][]gamedata.Position, 0)
+	)
+	permutations(append([]gamedata.Position{}, gamedata.Positions...), func(perm []gamedata.Position) {
+		total := 0.0
+		for i, pos := range perm {
+			total += s[i][pos]
+		}
+		if total > bestTotal {
+			bestTotal = total
+			copy(best, perm)
+		}
+		totals = append(totals, total)
+		perms = append(perms, append([]gamedata.Position{}, perm...))
+	})
+
+	agreeing := make([]float64, teamSize)
+	sum := 0.0
+	for i, perm := range perms {
+		// Shifted by the best total to avoid overflows
+		w := math.Exp(totals[i] - bestTotal)
+		sum += w
+		for j := range perm {
+			if perm[j] == best[j] {
+				agreeing[j] += w
+			}
+		}
+	}
+	assignments := make([]Assignment, teamSize)
+	for i := range assignments {
+		assignments[i] = Assignment{Position: best[i], Confidence: agreeing[i] / sum}
+	}
+	return assignments
+}
+
+// Infer assigns a position to every participant of the match, keyed by
+// participant ID. Teams that don't have exactly five participants are left out.
+func Infer(match *gamedata.MatchData, sd *gamedata.StaticData) map[int]Assignment {
+	teams := make(map[int][]*gamedata.Participant)
+	for i := range match.Info.Participants {
+		p := &match.Info.Participants[i]
+		teams[p.TeamId] = append(teams[p.TeamId], p)
+	}
+	result := make(map[int]Assignment, len(match.Info.Participants))
+	for _, team := range teams {
+		if len(team) != teamSize {
+			continue
+		}
+		for i, a := range inferTeam(team, gameMinutes(match), sd) {
+			result[team[i].ParticipantId] = a
+		}
+	}
+	return result
+}
+
+// Complete sets the inferred position of the participants whose position
+// isn't given by Riot's fields, and returns how many were completed.
+func Complete(match *gamedata.MatchData, sd *gamedata.StaticData) int {
+	var assignments map[int]Assignment
+	completed := 0
+	for i := range match.Info.Participants {
+		p := &match.Info.Participants[i]
+		if p.RiotPosition() != gamedata.PositionNone {
+			continue
+		}
+		if assignments == nil {
+			assignments = Infer(match, sd)
+		}
+		if a, ok := assignments[p.ParticipantId]; ok {
+			p.InferredPosition = a.Position
+			p.PositionConfidence = a.Confidence
+			completed++
+		}
+	}
+	return completed
+}
@@ -0,0 +1,149 @@
+package positions
+
+import (
+	"testing"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// participant returns a participant of team 100 with the CS of a laner over
+// 30 minutes and the given position, Invalid if none.
+func participant(id int, pos gamedata.Position) gamedata.Participant {
+	p := gamedata.Participant{ParticipantId: id, TeamId: 100, TotalMinionsKilled: 200, TeamPosition: string(pos)}
+	if pos == gamedata.PositionNone {
+		p.TeamPosition, p.IndividualPosition = "Invalid", "Invalid"
+	}
+	return p
+}
+
+func match(minutes int, participants ...gamedata.Participant) *gamedata.MatchData {
+	m := &gamedata.MatchData{}
+	m.Info.GameDuration = minutes * 60
+	m.Info.GameEndTimestamp = 1
+	m.Info.Participants = participants
+	return m
+}
+
+func TestInfer(t *testing.T) {
+	top, jungle, mid, bot, sup := gamedata.PositionTop, gamedata.PositionJungle, gamedata.PositionMiddle, gamedata.PositionBottom, gamedata.PositionUtility
+	none := gamedata.PositionNone
+
+	smiter := participant(2, none)
+	smiter.Summoner1Id = smiteID
+	supporter := participant(5, none)
+	supporter.Item0 = 3850
+	supporter.TotalMinionsKilled = 20
+	junglerByCamps := participant(2, none)
+	junglerByCamps.TotalMinionsKilled, junglerByCamps.NeutralMinionsKilled = 20, 150
+	twoSmites := participant(1, none)
+	twoSmites.Summoner2Id = smiteID
+
+	tests := []struct {
+		name  string
+		match *gamedata.MatchData
+		want  []gamedata.Position
+		// Bounds of the confidence of every participant
+		minConfidence, maxConfidence float64
+	}{
+		{
+			name:          "all given",
+			match:         match(30, participant(1, top), participant(2, jungle), participant(3, mid), participant(4, bot), participant(5, sup)),
+			want:          []gamedata.Position{top, jungle, mid, bot, sup},
+			minConfidence: 0.99, maxConfidence: 1,
+		},
+		{
+			name:          "given in another order",
+			match:         match(30, participant(1, sup), participant(2, bot), participant(3, top), participant(4, jungle), participant(5, mid)),
+			want:          []gamedata.Position{sup, bot, top, jungle, mid},
+			minConfidence: 0.99, maxConfidence: 1,
+		},
+		{
+			name:          "one missing",
+			match:         match(30, participant(1, top), smiter, participant(3, mid), participant(4, bot), participant(5, sup)),
+			want:          []gamedata.Position{top, jungle, mid, bot, sup},
+			minConfidence: 0.99, maxConfidence: 1,
+		},
+		{
+			name:          "two missing, told by smite and the support item",
+			match:         match(30, participant(1, top), smiter, participant(3, mid), participant(4, bot), supporter),
+			want:          []gamedata.Position{top, jungle, mid, bot, sup},
+			minConfidence: 0.99, maxConfidence: 1,
+		},
+		{
+			name:          "jungler told by the jungle camps",
+			match:         match(30, participant(1, top), junglerByCamps, participant(3, mid), participant(4, bot), participant(5, sup)),
+			want:          []gamedata.Position{top, jungle, mid, bot, sup},
+			minConfidence: 0.99, maxConfidence: 1,
+		},
+		{
+			// Every assignment has the same weight, the first one is kept
+			name:          "all missing",
+			match:         match(0, participant(1, none), participant(2, none), participant(3, none), participant(4, none), participant(5, none)),
+			want:          []gamedata.Position{top, jungle, mid, bot, sup},
+			minConfidence: 0.2, maxConfidence: 0.2,
+		},
+		{
+			// Both can be the jungler, the first assignment found is kept
+			name:          "two smites",
+			match:         match(0, twoSmites, smiter, participant(3, none), participant(4, none), participant(5, none)),
+			want:          []gamedata.Position{top, jungle, mid, bot, sup},
+			minConfidence: 0.1, maxConfidence: 0.5,
+		},
+	}
+	sd := &gamedata.StaticData{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Infer(tt.match, sd)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Infer() assigned %d participants, want %d", len(got), len(tt.want))
+			}
+			for i, p := range tt.match.Info.Participants {
+				a := got[p.ParticipantId]
+				if a.Position != tt.want[i] {
+					t.Errorf("participant %d got %s, want %s", p.ParticipantId, a.Position, tt.want[i])
+				}
+				if a.Confidence < tt.minConfidence || a.Confidence > tt.maxConfidence {
+					t.Errorf("participant %d confidence %v, want within [%v, %v]", p.ParticipantId, a.Confidence, tt.minConfidence, tt.maxConfidence)
+				}
+			}
+		})
+	}
+}
+
+func TestInferIncompleteTeam(t *testing.T) {
+	m := match(30, participant(1, gamedata.PositionTop), participant(2, gamedata.PositionJungle))
+	if got := Infer(m, &gamedata.StaticData{}); len(got) != 0 {
+		t.Errorf("Infer() = %v for a team of 2, want no assignment", got)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	smiter := participant(2, gamedata.PositionNone)
+	smiter.Summoner1Id = smiteID
+	m := match(30, participant(1, gamedata.PositionTop), smiter, participant(3, gamedata.PositionMiddle),
+		participant(4, gamedata.PositionBottom), participant(5, gamedata.PositionUtility))
+	if n := Complete(m, &gamedata.StaticData{}); n != 1 {
+		t.Fatalf("Complete() = %d, want 1", n)
+	}
+	for _, p := range m.Info.Participants {
+		if p.ParticipantId != 2 && p.InferredPosition != gamedata.PositionNone {
+			t.Errorf("participant %d with a position got %s inferred", p.ParticipantId, p.InferredPosition)
+		}
+	}
+	if p := m.Info.Participants[1]; p.Position() != gamedata.PositionJungle {
+		t.Errorf("participant 2 is %s, want %s", p.Position(), gamedata.PositionJungle)
+	}
+}
+
+func TestGameMinutes(t *testing.T) {
+	m := &gamedata.MatchData{}
+	// Before patch 11.20, in milliseconds
+	m.Info.GameDuration = 1800000
+	if got := gameMinutes(m); got != 30 {
+		t.Errorf("gameMinutes() = %v for a legacy match, want 30", got)
+	}
+	m.Info.GameDuration, m.Info.GameEndTimestamp = 1800, 1
+	if got := gameMinutes(m); got != 30 {
+		t.Errorf("gameMinutes() = %v, want 30", got)
+	}
+}
@@ -1,7 +1,35 @@
 package printer
 
+import "os"
+
 var globalPrint = NewPrint(LevelDebug)
 
+// SetOutput changes the file the standard messages are written to, e.g.
+// os.Stderr when os.Stdout is used to output data.
+func SetOutput(out *os.File) {
+	globalPrint.SetOutput(out)
+}
+
+// SetLogLevel changes the most verbose level of the printed log messages.
+func SetLogLevel(level int) {
+	globalPrint.SetLogLevel(level)
+}
+
+// KeepRecent keeps the last n warnings and errors, even the ones not printed
+// because of the log level, 0 stopping it.
+func KeepRecent(n int) {
+	globalPrint.KeepRecent(n)
+}
+
+// Recent returns the warnings and errors kept, the oldest first.
+func Recent() []string {
+	return globalPrint.Recent()
+}
+
+func GetLogLevel() int {
+	return globalPrint.GetLogLevel()
+}
+
 func Printf(p string, a ...any) {
 	globalPrint.WriteToStdf(p, a...)
 }
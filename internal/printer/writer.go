@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,18 +15,22 @@ type Writer struct {
 	out      *os.File
 	in       *os.File
 	err      *os.File
-	logLevel int
+	logLevel atomic.Int32
 	mx       *sync.RWMutex
+	// Latest warnings and errors, whatever the log level, if keep > 0
+	recent []string
+	keep   int
 }
 
 func NewPrint(loglevel int) *Writer {
-	return &Writer{
-		out:      os.Stdout,
-		in:       os.Stdin,
-		err:      os.Stderr,
-		logLevel: loglevel,
-		mx:       &sync.RWMutex{},
+	w := &Writer{
+		out: os.Stdout,
+		in:  os.Stdin,
+		err: os.Stderr,
+		mx:  &sync.RWMutex{},
 	}
+	w.logLevel.Store(int32(loglevel))
+	return w
 }
 
 const (
@@ -40,6 +45,9 @@ const (
 	LevelDebug
 )
 
+// LevelSilent disables every log message.
+const LevelSilent = LevelError - 1
+
 var bufferPool = sync.Pool{
 	New: func() interface{} {
 		return &bytes.Buffer{}
@@ -127,41 +135,81 @@ func (l *Writer) WriteToErrf(format string, a ...any) {
 	l.WriteToError(b)
 }
 
+// SetOutput changes the file the standard messages are written to.
+func (l *Writer) SetOutput(out *os.File) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.out = out
+}
+
 func (l *Writer) SetLogLevel(level int) {
-	l.logLevel = level
+	l.logLevel.Store(int32(level))
 }
 
 func (l *Writer) GetLogLevel() int {
-	return l.logLevel
+	return int(l.logLevel.Load())
 }
 
 func (l *Writer) formatPrefix(level string) string {
 	return "[" + strconv.FormatUint(getGoroutineID(), 10) + " | " + time.Now().Format("15:04:05.000") + " | " + level + "]"
 }
 
+// KeepRecent makes the writer keep the last n warnings and errors, even the
+// ones not printed because of the log level. 0 stops keeping them.
+func (l *Writer) KeepRecent(n int) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.keep = n
+	if len(l.recent) > n {
+		l.recent = l.recent[len(l.recent)-n:]
+	}
+}
+
+// Recent returns the warnings and errors kept, the oldest first.
+func (l *Writer) Recent() []string {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+	return append([]string(nil), l.recent...)
+}
+
+func (l *Writer) record(level, format string, a ...interface{}) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	if l.keep == 0 {
+		return
+	}
+	msg := time.Now().Format("15:04:05") + " " + level + " " + fmt.Sprintf(format, a...)
+	l.recent = append(l.recent, msg)
+	if len(l.recent) > l.keep {
+		l.recent = l.recent[len(l.recent)-l.keep:]
+	}
+}
+
 func (l *Writer) Error(format string, a ...interface{}) {
-	if l.logLevel >= LevelError {
+	l.record("ERROR", format, a...)
+	if l.GetLogLevel() >= LevelError {
 		msg := fmt.Sprintf("{-F_RED,BOLD}"+l.formatPrefix("ERROR")+" {-RESET}"+format, a...)
 		l.write([]byte(msg), l.err)
 	}
 }
 
 func (l *Writer) Warn(format string, a ...interface{}) {
-	if l.logLevel >= LevelWarn {
+	l.record("WARN", format, a...)
+	if l.GetLogLevel() >= LevelWarn {
 		msg := fmt.Sprintf("{-F_YELLOW,BOLD}"+l.formatPrefix("WARN")+" {-RESET}"+format, a...)
 		l.write([]byte(msg), l.out)
 	}
 }
 
 func (l *Writer) Info(format string, a ...interface{}) {
-	if l.logLevel >= LevelInfo {
+	if l.GetLogLevel() >= LevelInfo {
 		msg := fmt.Sprintf("{-F_BLUE,BOLD}"+l.formatPrefix("INFO")+" {-RESET}"+format, a...)
 		l.write([]byte(msg), l.out)
 	}
 }
 
 func (l *Writer) Debug(format string, a ...interface{}) {
-	if l.logLevel >= LevelDebug {
+	if l.GetLogLevel() >= LevelDebug {
 		msg := fmt.Sprintf("{-F_CYAN,BOLD}"+l.formatPrefix("DEBUG")+" {-RESET}"+format, a...)
 		l.write([]byte(msg), l.out)
 	}
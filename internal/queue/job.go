@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrNoHandler = errors.New("no handler registered for the job kind")
+
+// Job describes a task run by a pool. It's plain data, so that it can be
+// spilled to disk, the pool running it with the handler of its kind.
+type Job struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Jobs of higher priority are run first
+	Priority int `json:"priority"`
+	// The job is skipped after the deadline, if set
+	Deadline time.Time       `json:"deadline,omitempty"`
+	Attempts int             `json:"attempts"`
+	Payload  json.RawMessage `json:"payload"`
+	// Result set by the handler, or error of the last attempt
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// NewJob returns a job of the kind, with the payload encoded in JSON.
+func NewJob(kind string, priority int, payload any) (Job, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, err
+	}
+	return Job{ID: uuid.NewString(), Kind: kind, Priority: priority, Payload: b}, nil
+}
+
+// SetResult sets the result of the job, encoded in JSON.
+func (j *Job) SetResult(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	j.Result = b
+	return nil
+}
+
+// Expired reports whether the deadline of the job has passed.
+func (j *Job) Expired() bool {
+	return !j.Deadline.IsZero() && time.Now().After(j.Deadline)
+}
+
+// Handler runs the jobs of a kind. The context is cancelled when the pool
+// shutdown is forced or the deadline of the job passes.
+type Handler func(ctx context.Context, j *Job) error
+
+// RetryPolicy tells how the failed jobs of a kind are retried, the zero value
+// never retrying them.
+type RetryPolicy struct {
+	// Attempts after which the job is given up
+	MaxAttempts int
+	// Delay before the first retry, doubled after each attempt
+	Backoff time.Duration
+	// Reports whether the error is worth a retry, any error if nil
+	Retryable func(err error) bool
+}
+
+// JobError is the error of a job given up, sent to the errors channel of the
+// pool.
+type JobError struct {
+	Job Job
+	Err error
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("%s job %s (attempt %d): %v", e.Job.Kind, e.Job.ID, e.Job.Attempts, e.Err)
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
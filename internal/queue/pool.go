@@ -1,105 +1,385 @@
 package queue
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"LoLItemRecommender/internal/printer"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrPoolClosed    = errors.New("pool shut down")
+	ErrInvalidBounds = errors.New("invalid pool bounds, expected 1 <= min <= max")
+)
+
 type Pool struct {
-	q                  *Queue
-	cap                int
-	chanStopInProgress chan bool
-	chanErr            chan error
-	chanWorkerDone     chan *Worker
-	workers            map[string]*Worker
+	q *Queue
+	// Guards the number of workers and its bounds
+	mu sync.Mutex
+	// Number of running workers, tending to target
+	size     int
+	target   int
+	min, max int
+	// Closed when the target decreases, for the idle workers to retire
+	resized chan struct{}
+	// Context of the jobs, cancelled when the shutdown is forced
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Closed when the shutdown starts
+	stop     chan struct{}
+	stopOnce sync.Once
+	// Wakes up a worker waiting for a job
+	wake      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	// Number of workers running a job
+	active   atomic.Int32
+	chanErr  chan error
+	handlers map[string]Handler
+	policies map[string]RetryPolicy
+	// Number of failed jobs waiting for their retry
+	retrying atomic.Int32
+}
+
+// Register sets the handler of the jobs of the kind, before they're
+// dispatched.
+func (p *Pool) Register(kind string, h Handler) {
+	p.handlers[kind] = h
+}
+
+// SetRetryPolicy sets how the failed jobs of the kind are retried.
+func (p *Pool) SetRetryPolicy(kind string, policy RetryPolicy) {
+	p.policies[kind] = policy
+}
+
+// SpillTo makes the pool write the queued jobs beyond its in-memory limit to
+// a temporary file of the directory, instead of keeping them in memory.
+func (p *Pool) SpillTo(dir string) error {
+	return p.q.SpillTo(dir)
+}
+
+func (p *Pool) stopping() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// run runs the job, scheduling its retry if it fails. The error returned is
+// the one of a job given up, a job whose deadline passed being skipped.
+func (p *Pool) run(j Job) error {
+	h, ok := p.handlers[j.Kind]
+	if !ok {
+		return &JobError{Job: j, Err: ErrNoHandler}
+	}
+	if j.Expired() {
+		printer.Debug("%s job %s skipped, deadline passed", j.Kind, j.ID)
+		return nil
+	}
+	ctx := p.ctx
+	if !j.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, j.Deadline)
+		defer cancel()
+	}
+	j.Attempts++
+	err := h(ctx, &j)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) && j.Expired() {
+		printer.Debug("%s job %s stopped, deadline passed", j.Kind, j.ID)
+		return nil
+	}
+	j.Error = err.Error()
+	if p.retry(j, err) {
+		return nil
+	}
+	return &JobError{Job: j, Err: err}
 }
 
+// retry dispatches the failed job again after its backoff, if its retry
+// policy allows it, its deadline hasn't passed and the pool isn't shutting
+// down.
+func (p *Pool) retry(j Job, err error) bool {
+	policy := p.policies[j.Kind]
+	if p.stopping() || j.Expired() || j.Attempts >= policy.MaxAttempts || (policy.Retryable != nil && !policy.Retryable(err)) {
+		return false
+	}
+	delay := policy.Backoff << (j.Attempts - 1)
+	printer.Warn("%s job %s failed, attempt %d/%d, retrying in %s: %v", j.Kind, j.ID, j.Attempts, policy.MaxAttempts, delay, err)
+	p.retrying.Add(1)
+	time.AfterFunc(delay, func() {
+		defer p.retrying.Add(-1)
+		p.Dispatch(j)
+	})
+	return true
+}
+
+// report sends the error of a job, dropped if the shutdown is forced.
+func (p *Pool) report(err error) {
+	select {
+	case p.chanErr <- err:
+	case <-p.ctx.Done():
+	}
+}
+
+// GetErrorsChan returns the errors of the jobs given up. It has to be read
+// until it's closed by Shutdown.
 func (p *Pool) GetErrorsChan() <-chan error {
 	return p.chanErr
 }
 
-func (p *Pool) Close() {
-	p.chanStopInProgress <- true
-	for id, w := range p.workers {
-		printer.Debug("Worker %s stopped", id)
-		w.Stop()
+// Shutdown stops the workers once their running job is done, the queued jobs
+// being dropped, and returns once every worker has exited. If the context is
+// done first, the context of the running jobs is cancelled and its error is
+// returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	p.mu.Unlock()
+	exited := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(exited)
+	}()
+	var err error
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		err = ctx.Err()
+		p.cancel()
+		<-exited
 	}
-	close(p.chanStopInProgress)
-	close(p.chanErr)
-	close(p.chanWorkerDone)
+	p.closeOnce.Do(func() {
+		p.cancel()
+		close(p.chanErr)
+		if err := p.q.Close(); err != nil {
+			printer.Error("Unable to remove the spilled jobs: %v", err)
+		}
+	})
+	return err
 }
 
-func (p *Pool) Dispatch(j JobTodo) {
-	w := p.GetFirstAsleepWorker()
-	if w != nil {
-		w.ChangeState(StatePending)
-		printer.Debug("[Dispatch] Attributing to %p", w)
-		w.Attribute(j)
-	} else {
-		printer.Debug("[Dispatch] No worker available for this job, adding to the queue (%p)", j)
-		p.q.AddJob(j)
+// identify gives an ID to the job built without NewJob.
+func identify(j Job) Job {
+	if j.ID == "" {
+		j.ID = uuid.NewString()
 	}
+	return j
 }
 
-func (p *Pool) GetFirstAsleepWorker() *Worker {
-	for _, w := range p.workers {
-		if w.GetState() == StateAsleep {
-			return w
-		}
+// notify wakes up a worker waiting for a job, if any.
+func (p *Pool) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
 	}
-	return nil
 }
 
-func (p *Pool) dequeueJob(initEnded chan<- bool) {
-	initEnded <- true
+// next returns the job to run, waiting for one if the queue is empty. It
+// returns false once the pool is shutting down.
+func (p *Pool) next() (Job, bool) {
 	for {
-		select {
-		case w := <-p.chanWorkerDone:
-			j := p.q.PopLastJob()
-			if j != nil {
-				w.ChangeState(StatePending)
-				w.Attribute(j.todo)
+		// Read with the retire check, for a shrink in between not to be missed
+		retire, resized := p.retire()
+		if p.stopping() || retire {
+			return Job{}, false
+		}
+		// Counted before the pop, for the job never to be missed by
+		// WaitJobsToComplete
+		p.active.Add(1)
+		if j, ok := p.q.PopJob(); ok {
+			if !p.q.Empty() {
+				p.notify()
 			}
-		case <-p.chanStopInProgress:
-			return
+			return j, true
+		}
+		p.active.Add(-1)
+		select {
+		case <-p.wake:
+		case <-resized:
+		case <-p.stop:
+			return Job{}, false
 		}
 	}
 }
 
-func (p *Pool) allWorkerAreDone() bool {
-	for _, w := range p.workers {
-		if w.GetState() != StateAsleep {
-			return false
+// retire reports whether the worker has to exit, the pool having more workers
+// than its target. Otherwise, it returns the channel closed when the target
+// decreases next.
+func (p *Pool) retire() (bool, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.size <= p.target {
+		return false, p.resized
+	}
+	p.size--
+	// The wake up may have been meant for a job
+	if !p.q.Empty() {
+		p.notify()
+	}
+	return true, nil
+}
+
+// resize sets the number of workers, within the bounds, and returns it. The
+// new workers are started right away, the ones in excess exit once their job
+// is done.
+func (p *Pool) resize(n int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resizeLocked(n)
+	return p.target
+}
+
+func (p *Pool) resizeLocked(n int) {
+	if p.stopping() {
+		return
+	}
+	if n < p.min {
+		n = p.min
+	}
+	if n > p.max {
+		n = p.max
+	}
+	if n < p.target {
+		close(p.resized)
+		p.resized = make(chan struct{})
+	}
+	p.target = n
+	for p.size < p.target {
+		p.size++
+		w := NewWorker(p)
+		p.wg.Add(1)
+		go w.ListenJobs()
+		printer.Debug("Worker {-F_GREEN}%s{-RESET} ready", w.id)
+	}
+}
+
+// SetBounds sets the minimum and maximum number of workers, the current
+// number being brought within them.
+func (p *Pool) SetBounds(min, max int) error {
+	if min < 1 || max < min {
+		return ErrInvalidBounds
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.min, p.max = min, max
+	p.resizeLocked(p.target)
+	return nil
+}
+
+// targetSize returns the number of workers the pool tends to.
+func (p *Pool) targetSize() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.target
+}
+
+// Bounds returns the minimum and maximum number of workers.
+func (p *Pool) Bounds() (min, max int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.min, p.max
+}
+
+// Dispatch runs the job, before the queued jobs of lower priority. It never
+// blocks, the jobs beyond the in-memory limit being spilled to disk. The job
+// is dropped if the pool is shutting down.
+func (p *Pool) Dispatch(j Job) {
+	j = identify(j)
+	if p.stopping() {
+		printer.Debug("[Dispatch] Pool shutting down, dropping %s job %s", j.Kind, j.ID)
+		return
+	}
+	p.q.AddJob(j)
+	p.notify()
+}
+
+// Submit runs the job like Dispatch, but waits for room in the in-memory
+// queue when it's full, until the context is done.
+func (p *Pool) Submit(ctx context.Context, j Job) error {
+	j = identify(j)
+	for {
+		if p.stopping() {
+			return ErrPoolClosed
+		}
+		freed, ok := p.q.TryAddJob(j)
+		if ok {
+			p.notify()
+			return nil
+		}
+		select {
+		case <-freed:
+		case <-p.stop:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
-	return true
 }
 
+// Cap returns the number of workers.
+func (p *Pool) Cap() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// ActiveWorkers returns the number of workers running a job.
+func (p *Pool) ActiveWorkers() int {
+	return int(p.active.Load())
+}
+
+// Pending returns the number of jobs waiting for a worker, in memory and on
+// disk.
+func (p *Pool) Pending() int {
+	return int(p.q.Size())
+}
+
+// Deferred returns the number of jobs spilled to disk so far.
+func (p *Pool) Deferred() int {
+	return p.q.Deferred()
+}
+
+// WaitJobsToComplete returns once every job is done, or the pool is shutting
+// down.
 func (p *Pool) WaitJobsToComplete() {
-	for !p.q.Empty() || !p.allWorkerAreDone() {
-		time.Sleep(1 * time.Second)
+	for !p.q.Empty() || p.active.Load() > 0 || p.retrying.Load() > 0 {
+		select {
+		case <-time.After(1 * time.Second):
+		case <-p.stop:
+			return
+		}
 	}
 }
 
+// NewPool returns a pool of cap workers, until its bounds are changed by
+// SetBounds.
 func NewPool(cap int) *Pool {
 	printer.Info("Creating pool with a capacity of {-F_BLUE,BOLD}%d {-RESET}worker(s)", cap)
+	ctx, cancel := context.WithCancel(context.Background())
 	p := &Pool{
-		q:              NewQueue(),
-		cap:            cap,
-		workers:        make(map[string]*Worker, cap),
-		chanErr:        make(chan error),
-		chanWorkerDone: make(chan *Worker),
-	}
-	rdyQueueJob := make(chan bool)
-	for i := 0; i < cap; i++ {
-		id := uuid.NewString()
-		p.workers[id] = NewWorker(p.chanErr, p.chanWorkerDone)
-		printer.Info("Worker {-F_GREEN}%s{-RESET} (%p) ready", id, p.workers[id])
-	}
-	go p.dequeueJob(rdyQueueJob)
-	<-rdyQueueJob
+		q:        NewQueue(),
+		min:      cap,
+		max:      cap,
+		resized:  make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+		handlers: make(map[string]Handler),
+		policies: make(map[string]RetryPolicy),
+		chanErr:  make(chan error),
+	}
+	p.resize(cap)
 	return p
 }
 
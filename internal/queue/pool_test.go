@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolShutdown(t *testing.T) {
+	p := NewPool(1)
+	started := make(chan struct{})
+	var stopped error
+	p.Register("wait", func(ctx context.Context, j *Job) error {
+		close(started)
+		<-ctx.Done()
+		stopped = ctx.Err()
+		return ctx.Err()
+	})
+	p.Register("noop", func(context.Context, *Job) error {
+		t.Error("queued job run after the shutdown")
+		return nil
+	})
+	go func() {
+		for range p.GetErrorsChan() {
+		}
+	}()
+	p.Dispatch(Job{Kind: "wait"})
+	<-started
+
+	// The running job doesn't finish by itself, its context is cancelled
+	// once the grace period is over
+	p.Dispatch(Job{Kind: "noop", Priority: -1})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if !errors.Is(stopped, context.Canceled) {
+		t.Fatalf("job stopped with %v, want %v", stopped, context.Canceled)
+	}
+	if err := p.Submit(context.Background(), Job{Kind: "noop"}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Submit() after Shutdown = %v, want %v", err, ErrPoolClosed)
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown() = %v", err)
+	}
+}
+
+func TestPoolShutdownWaitsForJobs(t *testing.T) {
+	p := NewPool(1)
+	started := make(chan struct{})
+	done := make(chan struct{})
+	p.Register("sleep", func(ctx context.Context, j *Job) error {
+		close(started)
+		select {
+		case <-time.After(20 * time.Millisecond):
+			close(done)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	go func() {
+		for range p.GetErrorsChan() {
+		}
+	}()
+	p.Dispatch(Job{Kind: "sleep"})
+	<-started
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	select {
+	case <-done:
+	default:
+		t.Fatal("Shutdown() returned before the running job was done")
+	}
+}
+
+func TestPoolJobDeadline(t *testing.T) {
+	p := NewPool(1)
+	var runs atomic.Int32
+	p.Register("wait", func(ctx context.Context, j *Job) error {
+		runs.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	failed := errors.New("failed")
+	p.Register("fail", func(context.Context, *Job) error {
+		return failed
+	})
+	// Skipped without error, whether the deadline passes while running or
+	// before
+	p.Dispatch(Job{Kind: "wait", Priority: 2, Deadline: time.Now().Add(10 * time.Millisecond)})
+	p.Dispatch(Job{Kind: "wait", Priority: 1, Deadline: time.Now().Add(-time.Second)})
+	p.Dispatch(Job{Kind: "fail"})
+	err := <-p.GetErrorsChan()
+	go func() {
+		for err := range p.GetErrorsChan() {
+			t.Errorf("unexpected error %v", err)
+		}
+	}()
+	p.WaitJobsToComplete()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	if !errors.Is(err, failed) {
+		t.Errorf("error %v, want %v", err, failed)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("wait jobs run %d times, want once", n)
+	}
+}
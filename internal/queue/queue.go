@@ -1,74 +1,177 @@
 package queue
 
 import (
+	"container/heap"
 	"sync"
 
 	"LoLItemRecommender/internal/printer"
 )
 
-type JobTodo func() error
-type Job struct {
-	todo JobTodo
-	next *Job
-	prev *Job
+type item struct {
+	job Job
+	// Insertion order, the oldest job first among the same priority
+	seq uint64
 }
 
-func (j *Job) Do() error {
-	return j.todo()
+// jobHeap orders the jobs by priority, then insertion order.
+type jobHeap []*item
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].job.Priority != h[j].job.Priority {
+		return h[i].job.Priority > h[j].job.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x any)   { *h = append(*h, x.(*item)) }
+func (h *jobHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return it
 }
 
+// Queue holds the jobs waiting for a worker, the highest priority first. The
+// jobs beyond maxQueueLimitSize are spilled to disk if a spill directory is
+// set, kept in memory otherwise.
 type Queue struct {
-	m      *sync.RWMutex
-	root   *Job
-	last   *Job
-	length int32
+	m     *sync.RWMutex
+	jobs  jobHeap
+	seq   uint64
+	spill *spillFile
+	// Number of jobs spilled to disk
+	deferred int
+	// Closed when a job is popped
+	freed chan struct{}
 }
 
 func NewQueue() *Queue {
 	return &Queue{
-		m: &sync.RWMutex{},
+		m:     &sync.RWMutex{},
+		freed: make(chan struct{}),
 	}
 }
 
 const maxQueueLimitSize = 200
 
-func (q *Queue) AddJob(j JobTodo) {
+// SpillTo makes the queue write the jobs beyond its in-memory limit to a
+// temporary file of the directory.
+func (q *Queue) SpillTo(dir string) error {
+	s, err := newSpillFile(dir)
+	if err != nil {
+		return err
+	}
 	q.m.Lock()
 	defer q.m.Unlock()
-	if q.length > maxQueueLimitSize {
-		printer.Warn("Queue limit size reached, ignoring entry")
+	q.spill = s
+	return nil
+}
+
+func (q *Queue) push(j Job) {
+	q.seq++
+	heap.Push(&q.jobs, &item{job: j, seq: q.seq})
+}
+
+// AddJob queues the job. When the in-memory queue is full, the lowest
+// priority job is spilled to disk.
+func (q *Queue) AddJob(j Job) {
+	q.m.Lock()
+	defer q.m.Unlock()
+	if len(q.jobs) < maxQueueLimitSize || q.spill == nil {
+		q.push(j)
 		return
 	}
-	nj := &Job{next: q.root, todo: j}
-	if q.last == nil {
-		q.last = nj
+	spilled := j
+	if lowest := q.lowest(); q.jobs[lowest].job.Priority < j.Priority {
+		spilled = q.jobs[lowest].job
+		q.seq++
+		q.jobs[lowest] = &item{job: j, seq: q.seq}
+		heap.Fix(&q.jobs, lowest)
+	}
+	if err := q.spill.push(spilled); err != nil {
+		printer.Error("Unable to spill a job to disk, keeping it in memory: %v", err)
+		q.push(spilled)
+		return
 	}
-	if q.root != nil {
-		q.root.prev = nj
+	q.deferred++
+}
+
+// TryAddJob queues the job only if the in-memory queue isn't full. Otherwise,
+// it returns a channel closed when a job is popped.
+func (q *Queue) TryAddJob(j Job) (<-chan struct{}, bool) {
+	q.m.Lock()
+	defer q.m.Unlock()
+	if len(q.jobs) >= maxQueueLimitSize {
+		return q.freed, false
 	}
-	q.root = nj
-	q.length++
+	q.push(j)
+	return nil, true
+}
+
+// lowest returns the index of the job which would be popped last, one of the
+// leaves of the heap.
+func (q *Queue) lowest() int {
+	l := len(q.jobs) / 2
+	for i := l + 1; i < len(q.jobs); i++ {
+		if q.jobs.Less(l, i) {
+			l = i
+		}
+	}
+	return l
 }
 
 func (q *Queue) Empty() bool {
+	return q.Size() == 0
+}
+
+// Size returns the number of queued jobs, in memory and on disk.
+func (q *Queue) Size() int32 {
 	q.m.RLock()
 	defer q.m.RUnlock()
-	return q.length == 0
+	n := len(q.jobs)
+	if q.spill != nil {
+		n += q.spill.n
+	}
+	return int32(n)
 }
-func (q *Queue) Size() int32 {
+
+// Deferred returns the number of jobs spilled to disk so far.
+func (q *Queue) Deferred() int {
 	q.m.RLock()
 	defer q.m.RUnlock()
-	return q.length
+	return q.deferred
+}
+
+// PopJob removes and returns the highest priority job in memory, refilled
+// from disk once half empty.
+func (q *Queue) PopJob() (Job, bool) {
+	q.m.Lock()
+	defer q.m.Unlock()
+	if q.spill != nil && q.spill.n > 0 && len(q.jobs) < maxQueueLimitSize/2 {
+		jobs, err := q.spill.pop(maxQueueLimitSize - len(q.jobs))
+		if err != nil {
+			printer.Error("Unable to read the jobs spilled to disk: %v", err)
+		}
+		for _, j := range jobs {
+			q.push(j)
+		}
+	}
+	if len(q.jobs) == 0 {
+		return Job{}, false
+	}
+	close(q.freed)
+	q.freed = make(chan struct{})
+	return heap.Pop(&q.jobs).(*item).job, true
 }
 
-func (q *Queue) PopLastJob() *Job {
+// Close removes the spill file.
+func (q *Queue) Close() error {
 	q.m.Lock()
 	defer q.m.Unlock()
-	l := q.last
-	if l == nil {
+	if q.spill == nil {
 		return nil
 	}
-	q.last = q.last.prev
-	q.length--
-	return l
+	return q.spill.close()
 }
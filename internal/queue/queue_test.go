@@ -0,0 +1,121 @@
+package queue
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func popAll(t *testing.T, q *Queue) []Job {
+	t.Helper()
+	var jobs []Job
+	for {
+		j, ok := q.PopJob()
+		if !ok {
+			return jobs
+		}
+		jobs = append(jobs, j)
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	q := NewQueue()
+	for i, p := range []int{1, 3, 2, 3, 1} {
+		q.AddJob(Job{ID: strconv.Itoa(i), Priority: p})
+	}
+	var got []string
+	for _, j := range popAll(t, q) {
+		got = append(got, j.ID)
+	}
+	want := []string{"1", "3", "2", "0", "4"}
+	if len(got) != len(want) {
+		t.Fatalf("popped %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("popped %v, want %v", got, want)
+		}
+	}
+}
+
+func TestQueueSpill(t *testing.T) {
+	q := NewQueue()
+	if err := q.SpillTo(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	// The lower the index, the higher the priority, added from the lowest
+	const n = 3 * maxQueueLimitSize
+	for i := n - 1; i >= 0; i-- {
+		q.AddJob(Job{ID: strconv.Itoa(i), Priority: n - i, Payload: []byte(`{"i":` + strconv.Itoa(i) + `}`)})
+	}
+	if q.Deferred() == 0 {
+		t.Fatal("no job spilled to disk")
+	}
+	if got := q.Size(); got != n {
+		t.Fatalf("Size() = %d, want %d", got, n)
+	}
+	jobs := popAll(t, q)
+	if len(jobs) != n {
+		t.Fatalf("popped %d jobs, want %d", len(jobs), n)
+	}
+	// The in-memory jobs come first, by priority, then the spilled ones
+	for i := 0; i < maxQueueLimitSize; i++ {
+		if jobs[i].ID != strconv.Itoa(i) {
+			t.Fatalf("job %d is %s, want %d", i, jobs[i].ID, i)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, j := range jobs {
+		if seen[j.ID] {
+			t.Fatalf("job %s popped twice", j.ID)
+		}
+		seen[j.ID] = true
+		if want := `{"i":` + j.ID + `}`; string(j.Payload) != want {
+			t.Fatalf("payload of job %s is %s, want %s", j.ID, j.Payload, want)
+		}
+	}
+	if !q.Empty() {
+		t.Fatalf("Size() = %d after popping every job", q.Size())
+	}
+}
+
+func TestSpillDeadLetters(t *testing.T) {
+	s, err := newSpillFile(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	if err := s.push(Job{ID: "0"}); err != nil {
+		t.Fatal(err)
+	}
+	bad := []byte("{not json\n")
+	if _, err := s.f.WriteAt(bad, s.writeOff); err != nil {
+		t.Fatal(err)
+	}
+	s.writeOff += int64(len(bad))
+	s.n++
+	if err := s.push(Job{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := s.pop(3)
+	if err == nil || len(jobs) != 1 || jobs[0].ID != "0" {
+		t.Fatalf("pop() = %v, %v, want job 0 and an error", jobs, err)
+	}
+	b, err := os.ReadFile(s.deadLetters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(s.deadLetters())
+	if string(b) != string(bad) {
+		t.Fatalf("dead letters are %q, want %q", b, bad)
+	}
+	jobs, err = s.pop(3)
+	if err != nil || len(jobs) != 1 || jobs[0].ID != "2" {
+		t.Fatalf("pop() = %v, %v, want job 2", jobs, err)
+	}
+	if s.n != 0 {
+		t.Fatalf("%d jobs left on disk, want 0", s.n)
+	}
+}
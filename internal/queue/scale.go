@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"time"
+
+	"LoLItemRecommender/internal/printer"
+)
+
+const (
+	// Share of the time the workers spend waiting for the rate limit above
+	// which workers are removed, and below which they can be added
+	throttleHigh = 0.5
+	throttleLow  = 0.1
+	// Mean write latency above which workers are removed
+	slowWrites = 500 * time.Millisecond
+)
+
+// Load is measured by the jobs since the previous sample.
+type Load struct {
+	// Time spent by the workers waiting for the rate limit
+	RateLimitWait time.Duration
+	// Mean latency of the database writes
+	WriteLatency time.Duration
+}
+
+// AutoScale samples the load every interval and adds workers while jobs are
+// waiting, removing them when they pile up on the rate limit or the database,
+// or when they're idle. It returns once the pool is shut down.
+func (p *Pool) AutoScale(interval time.Duration, sample func() Load) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-p.stop:
+			return
+		}
+		l := sample()
+		n := p.targetSize()
+		throttled := float64(l.RateLimitWait) / float64(interval*time.Duration(n))
+		target := scale(n, p.Pending(), p.ActiveWorkers(), throttled, l.WriteLatency)
+		if target == n {
+			continue
+		}
+		if c := p.resize(target); c != n {
+			printer.Info("Pool scaled from {-F_BLUE,BOLD}%d {-RESET}to {-F_BLUE,BOLD}%d {-RESET}workers (%d pending, %.0f%% rate limited, writes %s)",
+				n, c, p.Pending(), throttled*100, l.WriteLatency.Round(time.Millisecond))
+		}
+	}
+}
+
+// scale returns the number of workers wanted by the pool of n workers.
+func scale(n, pending, active int, throttled float64, writeLatency time.Duration) int {
+	switch {
+	case throttled > throttleHigh, writeLatency > slowWrites:
+		return n - 1
+	case pending > 0 && throttled < throttleLow:
+		return n + 1 + n/4
+	case pending == 0 && active < n/2:
+		return n - 1
+	}
+	return n
+}
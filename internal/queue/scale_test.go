@@ -0,0 +1,37 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		name         string
+		n            int
+		pending      int
+		active       int
+		throttled    float64
+		writeLatency time.Duration
+		want         int
+	}{
+		{"jobs waiting", 4, 10, 4, 0, 0, 6},
+		{"jobs waiting, single worker", 1, 10, 1, 0, 0, 2},
+		{"rate limited", 4, 10, 4, 0.6, 0, 3},
+		{"slow writes", 4, 10, 4, 0, time.Second, 3},
+		{"slow writes, idle", 4, 0, 0, 0, time.Second, 3},
+		{"idle", 4, 0, 1, 0, 0, 3},
+		{"busy, no jobs waiting", 4, 0, 4, 0, 0, 4},
+		{"jobs waiting, some throttling", 4, 10, 4, 0.3, 0, 4},
+		{"throttling at the high threshold", 4, 10, 4, throttleHigh, 0, 4},
+		{"throttling at the low threshold", 4, 10, 4, throttleLow, 0, 4},
+		{"writes at the threshold", 4, 10, 4, 0, slowWrites, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scale(tt.n, tt.pending, tt.active, tt.throttled, tt.writeLatency); got != tt.want {
+				t.Errorf("scale(%d, %d, %d, %v, %s) = %d, want %d", tt.n, tt.pending, tt.active, tt.throttled, tt.writeLatency, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// spillFile is the on-disk part of a queue, the jobs beyond its in-memory
+// limit being appended to it as JSON lines and read back in the same order.
+type spillFile struct {
+	f *os.File
+	// Offsets of the next job to read and of the end of the file
+	readOff  int64
+	writeOff int64
+	// Number of jobs on disk
+	n int
+}
+
+func newSpillFile(dir string) (*spillFile, error) {
+	f, err := os.CreateTemp(dir, "queue-*.ndjson")
+	if err != nil {
+		return nil, err
+	}
+	return &spillFile{f: f}, nil
+}
+
+func (s *spillFile) push(j Job) error {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if _, err := s.f.WriteAt(b, s.writeOff); err != nil {
+		return err
+	}
+	s.writeOff += int64(len(b))
+	s.n++
+	return nil
+}
+
+// pop reads at most n jobs. A line which can't be decoded is moved to the
+// dead-letter file, next to the spill file, and reported by the error. The
+// file is truncated once every job is read.
+func (s *spillFile) pop(n int) ([]Job, error) {
+	r := bufio.NewReader(io.NewSectionReader(s.f, s.readOff, s.writeOff-s.readOff))
+	jobs := make([]Job, 0, n)
+	for len(jobs) < n && s.n > 0 {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return jobs, err
+		}
+		var j Job
+		if err := json.Unmarshal(line, &j); err != nil {
+			if berr := s.bury(line); berr != nil {
+				err = fmt.Errorf("undecodable job dropped, %v: %w", berr, err)
+			} else {
+				err = fmt.Errorf("undecodable job moved to %s: %w", s.deadLetters(), err)
+			}
+			return jobs, errors.Join(err, s.consume(line))
+		}
+		jobs = append(jobs, j)
+		if err := s.consume(line); err != nil {
+			return jobs, err
+		}
+	}
+	return jobs, nil
+}
+
+// consume commits the read of the line, truncating the file once every job
+// is read.
+func (s *spillFile) consume(line []byte) error {
+	s.readOff += int64(len(line))
+	s.n--
+	if s.n > 0 {
+		return nil
+	}
+	s.readOff, s.writeOff = 0, 0
+	return s.f.Truncate(0)
+}
+
+// deadLetters returns the name of the file keeping the lines which can't be
+// decoded, left in place when the queue is closed.
+func (s *spillFile) deadLetters() string {
+	return s.f.Name() + ".bad"
+}
+
+// bury appends the line to the dead-letter file.
+func (s *spillFile) bury(line []byte) error {
+	f, err := os.OpenFile(s.deadLetters(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *spillFile) close() error {
+	s.f.Close()
+	return os.Remove(s.f.Name())
+}
@@ -1,11 +1,12 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"LoLItemRecommender/internal/printer"
@@ -13,8 +14,9 @@ import (
 
 type Client struct {
 	client *http.Client
-	mx     *sync.RWMutex
 	limit  *Rate
+	// Time spent waiting for the rate limit
+	waited atomic.Int64
 }
 
 var ErrInvalidStatusCode = errors.New("invalid status code returned, expected 200 got %d")
@@ -22,31 +24,77 @@ var ErrInvalidStatusCode = errors.New("invalid status code returned, expected 20
 func NewClient() *Client {
 	return &Client{
 		client: &http.Client{},
-		mx:     &sync.RWMutex{},
 		limit:  NewRate(),
 	}
 }
 
+// StatusError is returned when the API doesn't answer with 200, it matches
+// ErrInvalidStatusCode with errors.Is.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf(ErrInvalidStatusCode.Error(), e.Code)
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrInvalidStatusCode
+}
+
 func generateErrorInvalidStatusCode(status int) error {
-	return fmt.Errorf(ErrInvalidStatusCode.Error(), status)
+	return &StatusError{Code: status}
+}
+
+// Calls returns the number of requests sent since the client was created.
+func (c *Client) Calls() int {
+	return c.limit.GetTotalUsage()
+}
+
+// Waited returns the total time spent waiting for the rate limit.
+func (c *Client) Waited() time.Duration {
+	return time.Duration(c.waited.Load())
 }
 
 func (c *Client) Get(url string) ([]byte, error) {
+	return c.GetContext(context.Background(), url)
+}
+
+// GetContext sends a GET request, giving up waiting for the rate limit or
+// the response when the context is done.
+func (c *Client) GetContext(ctx context.Context, url string) ([]byte, error) {
+	return c.get(ctx, url, nil)
+}
+
+// get sends a GET request, counted by the meter if not nil.
+func (c *Client) get(ctx context.Context, url string, m *Meter) ([]byte, error) {
 	canConsume, t := c.limit.CanConsumeTokens()
-	if !canConsume {
-		for !canConsume {
-			printer.Debug("Rate limit exceeded, sleeping for %v | %d tokens consumed", t, c.limit.GetTotalUsage())
-			time.Sleep(t)
-			printer.Debug("Waking up and try to consume tokens")
-			canConsume, t = c.limit.CanConsumeTokens()
+	for !canConsume {
+		printer.Debug("Rate limit exceeded, sleeping for %v | %d tokens consumed", t, c.limit.GetTotalUsage())
+		select {
+		case <-time.After(t):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.waited.Add(int64(t))
+		if m != nil {
+			m.waited.Add(int64(t))
 		}
+		printer.Debug("Waking up and try to consume tokens")
+		canConsume, t = c.limit.CanConsumeTokens()
 	}
-	c.mx.Lock()
-	defer c.mx.Unlock()
-	resp, err := c.client.Get(url)
+	if m != nil {
+		m.calls.Add(1)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	var b []byte
 	if b, err = io.ReadAll(resp.Body); err != nil {
 		return nil, err
@@ -61,3 +109,33 @@ func (c *Client) Get(url string) ([]byte, error) {
 		return nil, generateErrorInvalidStatusCode(resp.StatusCode)
 	}
 }
+
+// Meter counts the requests sent through a client shared with other users,
+// and the time they waited for its rate limit.
+type Meter struct {
+	client *Client
+	calls  atomic.Int64
+	waited atomic.Int64
+}
+
+// Meter returns a new meter of the requests sent through the client.
+func (c *Client) Meter() *Meter {
+	return &Meter{client: c}
+}
+
+// GetContext sends a GET request through the client, like
+// Client.GetContext.
+func (m *Meter) GetContext(ctx context.Context, url string) ([]byte, error) {
+	return m.client.get(ctx, url, m)
+}
+
+// Calls returns the number of requests sent through the meter.
+func (m *Meter) Calls() int {
+	return int(m.calls.Load())
+}
+
+// Waited returns the time the requests of the meter waited for the rate
+// limit.
+func (m *Meter) Waited() time.Duration {
+	return time.Duration(m.waited.Load())
+}
@@ -2,15 +2,17 @@ package api
 
 import (
 	"fmt"
+	"strings"
 )
 
 const (
 	// Summoner endpoints
 	summonerBySummonerNameEndpoint = "/summoner/v4/summoners/by-name/%s"
 	summonersByLeague              = "/league-exp/v4/entries/%s/%s/%s"
+	leagueEntriesBySummoner        = "/league/v4/entries/by-summoner/%s"
 
 	// Match endpoints
-	playerMatchListEndpoint = "/match/v5/matches/by-puuid/%s/ids?start=0&count=100" // TODO: Change count to be a parameter
+	playerMatchListEndpoint = "/match/v5/matches/by-puuid/%s/ids?start=%d&count=%d"
 	playerMatchInfoEndpoint = "/match/v5/matches/%s"
 
 	// Static data endpoints
@@ -19,8 +21,42 @@ const (
 	staticDDragonBaseURL        = "https://ddragon.leagueoflegends.com"
 
 	DDragonStaticVersionsURL = staticDDragonBaseURL + "/api/versions.json"
+
+	// Static documentation files of the developer portal
+	staticDocsBaseURL      = "https://static.developer.riotgames.com/docs/lol"
+	StaticDocsQueuesURL    = staticDocsBaseURL + "/queues.json"
+	StaticDocsMapsURL      = staticDocsBaseURL + "/maps.json"
+	StaticDocsGameModesURL = staticDocsBaseURL + "/gameModes.json"
 )
 
+// Regional routing values of the platforms, used by the match endpoints.
+var regionalRoutes = map[string]string{
+	"euw1": "europe",
+	"eun1": "europe",
+	"tr1":  "europe",
+	"ru":   "europe",
+	"me1":  "europe",
+	"na1":  "americas",
+	"br1":  "americas",
+	"la1":  "americas",
+	"la2":  "americas",
+	"kr":   "asia",
+	"jp1":  "asia",
+	"oc1":  "sea",
+	"ph2":  "sea",
+	"sg2":  "sea",
+	"th2":  "sea",
+	"tw2":  "sea",
+	"vn2":  "sea",
+}
+
+// RegionalRoute returns the regional routing value of the platform, e.g.
+// europe for euw1.
+func RegionalRoute(platform string) (string, bool) {
+	r, ok := regionalRoutes[strings.ToLower(platform)]
+	return r, ok
+}
+
 type EndpointsManager struct {
 	Apikey           string
 	Region           string
@@ -28,12 +64,18 @@ type EndpointsManager struct {
 	regionApiBaseURL string
 }
 
+// NewEndpointsManager returns the endpoints of the platform, europe being
+// used for the match endpoints of an unknown platform.
 func NewEndpointsManager(apikey, region string) *EndpointsManager {
+	route, ok := RegionalRoute(region)
+	if !ok {
+		route = "europe"
+	}
 	return &EndpointsManager{
 		Apikey:           apikey,
 		Region:           region,
 		apiBaseURL:       "https://" + region + ".api.riotgames.com/lol",
-		regionApiBaseURL: "https://europe.api.riotgames.com/lol",
+		regionApiBaseURL: "https://" + route + ".api.riotgames.com/lol",
 	}
 }
 
@@ -46,13 +88,31 @@ func (em *EndpointsManager) GetSummonersByLeague(queue, division, tier string, p
 	return fmt.Sprintf(em.apiBaseURL+summonersByLeague+"?page=%d&api_key=%s", queue, division, tier, page, em.Apikey)
 }
 
+// GetLeagueEntriesBySummoner returns the URL of the ranks of the summoner in
+// the ranked queues.
+func (em *EndpointsManager) GetLeagueEntriesBySummoner(summonerID string) string {
+	return fmt.Sprintf(em.apiBaseURL+leagueEntriesBySummoner+"?api_key=%s", summonerID, em.Apikey)
+}
+
 // GetMatchInfoURL returns the URL for the match info endpoint.
 func (em *EndpointsManager) GetMatchInfoURL(matchID string) string {
 	return fmt.Sprintf(em.regionApiBaseURL+playerMatchInfoEndpoint+"?api_key=%s", matchID, em.Apikey)
 }
 
+// MatchListPageSize is the number of match IDs in a page of the match list,
+// the most allowed by Riot.
+const MatchListPageSize = 100
+
+// GetMatchListURL returns the URL of the latest matches of the player.
 func (em *EndpointsManager) GetMatchListURL(pUUID string) string {
-	return fmt.Sprintf(em.regionApiBaseURL+playerMatchListEndpoint+"&api_key=%s", pUUID, em.Apikey)
+	return fmt.Sprintf(em.regionApiBaseURL+playerMatchListEndpoint+"&api_key=%s", pUUID, 0, MatchListPageSize, em.Apikey)
+}
+
+// GetMatchListSinceURL returns the URL of the page of the matches of the
+// player started after startTime, in epoch seconds, skipping the first start
+// ones.
+func (em *EndpointsManager) GetMatchListSinceURL(pUUID string, startTime int64, start int) string {
+	return fmt.Sprintf(em.regionApiBaseURL+playerMatchListEndpoint+"&startTime=%d&api_key=%s", pUUID, start, MatchListPageSize, startTime, em.Apikey)
 }
 
 // GetStaticDataChampionsURL returns the URL for the static data champions endpoint.
@@ -0,0 +1,114 @@
+package gamedata
+
+import (
+	"embed"
+	"encoding/json"
+
+	"LoLItemRecommender/internal/printer"
+	"LoLItemRecommender/internal/riotapi/api"
+)
+
+// Bundled copies of Riot's static documentation files, used when the
+// developer portal can't be reached.
+//
+//go:embed docs/*.json
+var bundledDocs embed.FS
+
+type QueueInfo struct {
+	QueueID     QueueID `json:"queueId"`
+	Map         string  `json:"map"`
+	Description string  `json:"description"`
+	Notes       string  `json:"notes"`
+}
+
+// SummonersRiftMapID is the map ID of the current Summoner's Rift.
+const SummonersRiftMapID = 11
+
+type MapInfo struct {
+	MapID   int    `json:"mapId"`
+	MapName string `json:"mapName"`
+	Notes   string `json:"notes"`
+}
+
+type GameModeInfo struct {
+	GameMode    string `json:"gameMode"`
+	Description string `json:"description"`
+}
+
+// retrieveDoc fetches a static documentation file and decodes it into v,
+// falling back to the bundled copy when the request or the decoding fails.
+func (sd *StaticData) retrieveDoc(url, bundled string, v any) error {
+	body, err := sd.client.Get(url)
+	if err == nil {
+		if err = json.Unmarshal(body, v); err == nil {
+			return nil
+		}
+	}
+	printer.Warn("Unable to retrieve '%s' (%v), using the bundled copy", url, err)
+	body, err = bundledDocs.ReadFile(bundled)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func (sd *StaticData) RetrieveQueues() error {
+	var queues []QueueInfo
+	if err := sd.retrieveDoc(api.StaticDocsQueuesURL, "docs/queues.json", &queues); err != nil {
+		return err
+	}
+	for i := range queues {
+		sd.Queues[queues[i].QueueID] = &queues[i]
+	}
+	printer.Printf("{-F_CYAN,BOLD}%d {-RESET}queues found", len(sd.Queues))
+	return nil
+}
+
+func (sd *StaticData) RetrieveMaps() error {
+	var maps []MapInfo
+	if err := sd.retrieveDoc(api.StaticDocsMapsURL, "docs/maps.json", &maps); err != nil {
+		return err
+	}
+	for i := range maps {
+		sd.Maps[maps[i].MapID] = &maps[i]
+	}
+	printer.Printf("{-F_CYAN,BOLD}%d {-RESET}maps found", len(sd.Maps))
+	return nil
+}
+
+func (sd *StaticData) RetrieveGameModes() error {
+	var modes []GameModeInfo
+	if err := sd.retrieveDoc(api.StaticDocsGameModesURL, "docs/gameModes.json", &modes); err != nil {
+		return err
+	}
+	for i := range modes {
+		sd.GameModes[modes[i].GameMode] = &modes[i]
+	}
+	printer.Printf("{-F_CYAN,BOLD}%d {-RESET}game modes found", len(sd.GameModes))
+	return nil
+}
+
+// RetrieveDocs loads the queues, maps and game modes documentation.
+func (sd *StaticData) RetrieveDocs() error {
+	for _, f := range []func() error{sd.RetrieveQueues, sd.RetrieveMaps, sd.RetrieveGameModes} {
+		if err := f(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetQueue returns the documentation of a queue, or nil if it is unknown.
+func (sd *StaticData) GetQueue(id QueueID) *QueueInfo {
+	return sd.Queues[id]
+}
+
+// GetMap returns the documentation of a map, or nil if it is unknown.
+func (sd *StaticData) GetMap(id int) *MapInfo {
+	return sd.Maps[id]
+}
+
+// GetGameMode returns the documentation of a game mode, or nil if it is unknown.
+func (sd *StaticData) GetGameMode(mode string) *GameModeInfo {
+	return sd.GameModes[mode]
+}
@@ -37,8 +37,10 @@ type Participant struct {
 	Item6                       int    `json:"item6"`
 	Kills                       int    `json:"kills"`
 	Lane                        string `json:"lane"`
+	NeutralMinionsKilled        int    `json:"neutralMinionsKilled"`
 	ParticipantId               int    `json:"participantId"`
 	Perks                       Perks  `json:"perks"`
+	Puuid                       string `json:"puuid"`
 	Role                        string `json:"role"`
 	Summoner1Id                 int    `json:"summoner1Id"`
 	Summoner2Id                 int    `json:"summoner2Id"`
@@ -47,7 +49,19 @@ type Participant struct {
 	SummonerName                string `json:"summonerName"`
 	TeamId                      int    `json:"teamId"`
 	TotalDamageDealtToChampions int    `json:"totalDamageDealtToChampions"`
+	TotalMinionsKilled          int    `json:"totalMinionsKilled"`
 	Win                         bool   `json:"win"`
+	TeamPosition                string `json:"teamPosition"`
+	IndividualPosition          string `json:"individualPosition"`
+
+	// Set by the position inference when Riot's fields don't give the position
+	InferredPosition   Position `json:"-"`
+	PositionConfidence float64  `json:"-"`
+}
+
+// Items returns the items of the inventory slots, including the trinket.
+func (p *Participant) Items() []int {
+	return []int{p.Item0, p.Item1, p.Item2, p.Item3, p.Item4, p.Item5, p.Item6}
 }
 
 type MatchData struct {
@@ -67,6 +81,6 @@ type MatchData struct {
 		MapId              int           `json:"mapId"`
 		Participants       []Participant `json:"participants"`
 		PlatformId         string        `json:"platformId"`
-		QueueId            int           `json:"queueId"`
+		QueueId            QueueID       `json:"queueId"`
 	} `json:"info"`
 }
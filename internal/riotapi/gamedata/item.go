@@ -6,6 +6,9 @@ type ItemData struct {
 	Colloq      string   `json:"colloq"`
 	Plaintext   string   `json:"plaintext"`
 	Into        []string `json:"into"`
+	From        []string `json:"from"`
+	Depth       int      `json:"depth"`
+	InStore     *bool    `json:"inStore"`
 	Image       struct {
 		Full   string `json:"full"`
 		Sprite string `json:"sprite"`
@@ -23,9 +26,32 @@ type ItemData struct {
 	} `json:"gold"`
 	Tags  []string     `json:"tags"`
 	Maps  map[int]bool `json:"maps"`
-	Stats struct {
-		FlatMovementSpeedMod int `json:"FlatMovementSpeedMod"`
-	} `json:"stats"`
+	Stats ItemStats    `json:"stats"`
+}
+
+// ItemStats holds the stats DDragon exposes for an item. Percentages are
+// expressed as ratios (0.25 for 25%).
+type ItemStats struct {
+	FlatHPPoolMod           float64 `json:"FlatHPPoolMod"`
+	FlatMPPoolMod           float64 `json:"FlatMPPoolMod"`
+	FlatHPRegenMod          float64 `json:"FlatHPRegenMod"`
+	PercentHPRegenMod       float64 `json:"PercentHPRegenMod"`
+	FlatMPRegenMod          float64 `json:"FlatMPRegenMod"`
+	PercentMPRegenMod       float64 `json:"PercentMPRegenMod"`
+	FlatArmorMod            float64 `json:"FlatArmorMod"`
+	FlatSpellBlockMod       float64 `json:"FlatSpellBlockMod"`
+	FlatPhysicalDamageMod   float64 `json:"FlatPhysicalDamageMod"`
+	FlatMagicDamageMod      float64 `json:"FlatMagicDamageMod"`
+	FlatMovementSpeedMod    float64 `json:"FlatMovementSpeedMod"`
+	PercentMovementSpeedMod float64 `json:"PercentMovementSpeedMod"`
+	PercentAttackSpeedMod   float64 `json:"PercentAttackSpeedMod"`
+	FlatCritChanceMod       float64 `json:"FlatCritChanceMod"`
+	PercentLifeStealMod     float64 `json:"PercentLifeStealMod"`
+}
+
+// IsPurchasable reports whether the item can be bought in the shop of the given map.
+func (i *ItemData) IsPurchasable(mapID int) bool {
+	return i.Gold.Purchasable && i.Gold.Total > 0 && i.Maps[mapID] && (i.InStore == nil || *i.InStore)
 }
 
 type ItemResponse struct {
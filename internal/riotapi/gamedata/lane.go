@@ -4,5 +4,6 @@ const (
 	LANE_TOP    = "TOP"
 	LANE_JUNGLE = "JUNGLE"
 	LANE_MID    = "MID"
+	LaneMiddle  = "MIDDLE"
 	LaneBottom  = "BOTTOM"
 )
@@ -0,0 +1,18 @@
+package gamedata
+
+import "strings"
+
+// Patch returns the major.minor part of a game or DDragon version, e.g. 14.1
+// for both 14.1.555.1234 and 14.1.1.
+func Patch(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// Patch returns the patch on which the match was played.
+func (m *MatchData) Patch() string {
+	return Patch(m.Info.GameVersion)
+}
@@ -11,4 +11,10 @@ type Player struct {
 	Puuid         string `json:"puuid"`
 	ProfileIconId int    `json:"profileIconId"`
 	RevisionDate  int64  `json:"revisionDate"`
+	// Crawl order, the highest first
+	Priority int `json:"-" db:"priority"`
+	// Epoch milliseconds before which the matches of the player are crawled
+	LastMatchTime int64 `json:"-" db:"last_match_time"`
+	// Crawled matches in which the player played a tracked champion
+	TrackedGames int `json:"-" db:"tracked_games"`
 }
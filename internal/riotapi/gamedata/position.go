@@ -0,0 +1,79 @@
+package gamedata
+
+// Position is the position played in a Summoner's Rift match, as given by the
+// teamPosition and individualPosition fields of match-v5.
+type Position string
+
+const (
+	PositionNone    Position = ""
+	PositionTop     Position = "TOP"
+	PositionJungle  Position = "JUNGLE"
+	PositionMiddle  Position = "MIDDLE"
+	PositionBottom  Position = "BOTTOM"
+	PositionUtility Position = "UTILITY"
+)
+
+var Positions = []Position{PositionTop, PositionJungle, PositionMiddle, PositionBottom, PositionUtility}
+
+// ParsePosition returns the position named s, or false if s isn't a valid
+// position (match-v5 uses "Invalid" when it couldn't decide).
+func ParsePosition(s string) (Position, bool) {
+	for _, p := range Positions {
+		if string(p) == s {
+			return p, true
+		}
+	}
+	return PositionNone, false
+}
+
+// LegacyPosition returns the position deduced from the lane and role fields,
+// or PositionNone if they don't tell it.
+func LegacyPosition(p *Participant) Position {
+	switch p.Lane {
+	case LANE_TOP:
+		return PositionTop
+	case LANE_JUNGLE:
+		return PositionJungle
+	case LANE_MID, LaneMiddle:
+		return PositionMiddle
+	case LaneBottom:
+		switch p.Role {
+		case RoleCarry:
+			return PositionBottom
+		case RoleSupport:
+			return PositionUtility
+		}
+	}
+	return PositionNone
+}
+
+// HasModernPosition reports whether the participant comes from a match
+// recent enough to have the teamPosition and individualPosition fields.
+func (p *Participant) HasModernPosition() bool {
+	return p.TeamPosition != "" || p.IndividualPosition != ""
+}
+
+// Position returns the position of the participant: teamPosition first, then
+// individualPosition. The lane and role fields are only used for the old
+// matches without these fields. The inferred position is used when none of
+// them tells the position.
+func (p *Participant) Position() Position {
+	if pos := p.RiotPosition(); pos != PositionNone {
+		return pos
+	}
+	return p.InferredPosition
+}
+
+// RiotPosition returns the position given by Riot's fields only.
+func (p *Participant) RiotPosition() Position {
+	if !p.HasModernPosition() {
+		return LegacyPosition(p)
+	}
+	if pos, ok := ParsePosition(p.TeamPosition); ok {
+		return pos
+	}
+	if pos, ok := ParsePosition(p.IndividualPosition); ok {
+		return pos
+	}
+	return PositionNone
+}
@@ -4,3 +4,115 @@ const (
 	RankedSolo5V5 = "RANKED_SOLO_5x5"
 	RankedFlexSr  = "RANKED_FLEX_SR"
 )
+
+// QueueID is the numeric queue identifier returned by match-v5 (info.queueId).
+type QueueID int
+
+const (
+	QueueCustom        QueueID = 0
+	QueueURF           QueueID = 76
+	QueueAllRandom     QueueID = 325
+	QueueNormalDraft   QueueID = 400
+	QueueRankedSolo    QueueID = 420
+	QueueNormalBlind   QueueID = 430
+	QueueRankedFlex    QueueID = 440
+	QueueARAM          QueueID = 450
+	QueueQuickplay     QueueID = 490
+	QueueClash         QueueID = 700
+	QueueARAMClash     QueueID = 720
+	QueueCoopIntro     QueueID = 830
+	QueueCoopBeginner  QueueID = 840
+	QueueCoopIntermed  QueueID = 850
+	QueueARURF         QueueID = 900
+	QueueOneForAll     QueueID = 1020
+	QueueNexusBlitz    QueueID = 1300
+	QueueUltimateSpell QueueID = 1400
+	QueueArena         QueueID = 1700
+	QueuePickURF       QueueID = 1900
+	QueueTFTNormal     QueueID = 1090
+	QueueTFTRanked     QueueID = 1100
+)
+
+const unknownQueueName = "Unknown queue"
+
+var queueNames = map[QueueID]string{
+	QueueCustom:        "Custom",
+	QueueURF:           "URF",
+	QueueAllRandom:     "All Random",
+	QueueNormalDraft:   "Normal Draft",
+	QueueRankedSolo:    "Ranked Solo/Duo",
+	QueueNormalBlind:   "Normal Blind",
+	QueueRankedFlex:    "Ranked Flex",
+	QueueARAM:          "ARAM",
+	QueueQuickplay:     "Quickplay",
+	QueueClash:         "Clash",
+	QueueARAMClash:     "ARAM Clash",
+	QueueCoopIntro:     "Co-op vs. AI Intro",
+	QueueCoopBeginner:  "Co-op vs. AI Beginner",
+	QueueCoopIntermed:  "Co-op vs. AI Intermediate",
+	QueueARURF:         "ARURF",
+	QueueOneForAll:     "One for All",
+	QueueNexusBlitz:    "Nexus Blitz",
+	QueueUltimateSpell: "Ultimate Spellbook",
+	QueueArena:         "Arena",
+	QueuePickURF:       "Pick URF",
+	QueueTFTNormal:     "Teamfight Tactics",
+	QueueTFTRanked:     "Ranked Teamfight Tactics",
+}
+
+// Name returns a short human-readable name for the queue.
+func (q QueueID) Name() string {
+	if n, ok := queueNames[q]; ok {
+		return n
+	}
+	return unknownQueueName
+}
+
+func (q QueueID) String() string {
+	return q.Name()
+}
+
+func (q QueueID) IsRankedSolo() bool {
+	return q == QueueRankedSolo
+}
+
+func (q QueueID) IsRankedFlex() bool {
+	return q == QueueRankedFlex
+}
+
+// IsRanked reports whether the queue is one of the Summoner's Rift ranked queues.
+func (q QueueID) IsRanked() bool {
+	return q.IsRankedSolo() || q.IsRankedFlex()
+}
+
+// IsDraft reports whether champions are picked through draft pick (bans and
+// pick order), which includes ranked queues and Clash.
+func (q QueueID) IsDraft() bool {
+	switch q {
+	case QueueNormalDraft, QueueRankedSolo, QueueRankedFlex, QueueClash:
+		return true
+	}
+	return false
+}
+
+func (q QueueID) IsARAM() bool {
+	return q == QueueARAM || q == QueueARAMClash
+}
+
+// IsRotatingMode reports whether the queue belongs to a limited-time game mode.
+func (q QueueID) IsRotatingMode() bool {
+	switch q {
+	case QueueURF, QueueAllRandom, QueueARURF, QueueOneForAll, QueueNexusBlitz, QueueUltimateSpell, QueueArena, QueuePickURF:
+		return true
+	}
+	return false
+}
+
+// IsSummonersRift reports whether the queue is played on the 5v5 Summoner's Rift with standard rules.
+func (q QueueID) IsSummonersRift() bool {
+	switch q {
+	case QueueNormalDraft, QueueRankedSolo, QueueNormalBlind, QueueRankedFlex, QueueQuickplay, QueueClash:
+		return true
+	}
+	return false
+}
@@ -4,4 +4,6 @@ const (
 	RoleNone  = "NONE"
 	RoleCarry = "CARRY"
 	RoleSolo  = "SOLO"
+	// Match-v5 uses SUPPORT where match-v4 used DUO_SUPPORT.
+	RoleSupport = "SUPPORT"
 )
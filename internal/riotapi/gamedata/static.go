@@ -2,17 +2,26 @@ package gamedata
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
+	"strconv"
+	"strings"
 
-	"LoLItemRecommender/internal/levenshtein"
 	"LoLItemRecommender/internal/printer"
 	"LoLItemRecommender/internal/riotapi/api"
+	"LoLItemRecommender/internal/search"
 )
 
 type StaticData struct {
 	APIVersion     string
 	ChampionsStats map[string]*ChampionStats
 	ItemsData      map[string]*ItemData
+	Queues         map[QueueID]*QueueInfo
+	Maps           map[int]*MapInfo
+	GameModes      map[string]*GameModeInfo
+	championsIndex *search.Index
+	championsByID  map[int]*ChampionStats
+	itemsIndex     *search.Index
 	client         *api.Client
 	em             *api.EndpointsManager
 	apiKey         string
@@ -25,42 +34,146 @@ func NewStaticData(em *api.EndpointsManager, client *api.Client) *StaticData {
 		client:         client,
 		ChampionsStats: make(map[string]*ChampionStats),
 		ItemsData:      make(map[string]*ItemData),
+		Queues:         make(map[QueueID]*QueueInfo),
+		Maps:           make(map[int]*MapInfo),
+		GameModes:      make(map[string]*GameModeInfo),
+		championsIndex: search.NewIndex(),
+		championsByID:  make(map[int]*ChampionStats),
+		itemsIndex:     search.NewIndex(),
 	}
 }
 
+// LoadStaticData retrieves the champions, items and documentation of the
+// given DDragon version, or of the latest one if version is empty.
+func LoadStaticData(em *api.EndpointsManager, client *api.Client, version string) (*StaticData, error) {
+	sd := NewStaticData(em, client)
+	if version == "" {
+		if err := sd.RetrieveAPIVersion(); err != nil {
+			return nil, err
+		}
+	} else {
+		sd.APIVersion = version
+	}
+	for _, f := range []func() error{sd.RetrieveChampionsStats, sd.RetrieveItems, sd.RetrieveDocs} {
+		if err := f(); err != nil {
+			return nil, err
+		}
+	}
+	return sd, nil
+}
+
 const (
 	StrongProbability = 70.0
 	WeakProbability   = 50.0
+	// Minimum score gap between the two best results to pick the first one
+	// without doubt.
+	ConfidenceMargin = 5.0
 )
 
-func (sd *StaticData) GetChampionStats(name string) *ChampionStats {
-	for c, s := range sd.ChampionsStats {
-		//printer.Debug("Similarity: %s & %s = %f", c, name, levenshtein.StringSimilarity(c, name))
-		if levenshtein.StringSimilarity(c, name) >= StrongProbability {
-			return s
+func (sd *StaticData) buildChampionsIndex() {
+	sd.championsIndex = search.NewIndex()
+	for id, c := range sd.ChampionsStats {
+		sd.championsIndex.Add(id, c.Name, id)
+	}
+}
+
+func (sd *StaticData) buildItemsIndex() {
+	sd.itemsIndex = search.NewIndex()
+	for id, item := range sd.ItemsData {
+		if !item.Maps[SummonersRiftMapID] {
+			continue
 		}
+		// Keep one entry per name, the same item exists for several maps.
+		if foundID, _ := sd.GetItemByName(item.Name); foundID != id {
+			continue
+		}
+		sd.itemsIndex.Add(id, item.Name, strings.Split(item.Colloq, ";")...)
 	}
-	return nil
+}
+
+// GetChampionByID returns the champion with the given numeric key, as used by
+// the match data, or nil if it is unknown.
+func (sd *StaticData) GetChampionByID(id int) *ChampionStats {
+	return sd.championsByID[id]
+}
+
+// SearchChampions returns the champions whose name is close to the query, the
+// closest first. The result keys are the ChampionsStats keys.
+func (sd *StaticData) SearchChampions(query string, limit int) []search.Result {
+	return sd.championsIndex.Search(query, WeakProbability, limit)
+}
+
+// SearchItems returns the Summoner's Rift items whose name or nickname is
+// close to the query, the closest first. The result keys are the ItemsData keys.
+func (sd *StaticData) SearchItems(query string, limit int) []search.Result {
+	return sd.itemsIndex.Search(query, WeakProbability, limit)
+}
+
+// GetChampionStats returns the champion matching the name if there is no
+// ambiguity with another champion.
+func (sd *StaticData) GetChampionStats(name string) *ChampionStats {
+	r := sd.SearchChampions(name, 2)
+	if !search.IsConfident(r, StrongProbability, ConfidenceMargin) {
+		return nil
+	}
+	return sd.ChampionsStats[r[0].Key]
 }
 
 func (sd *StaticData) GetChampionsStatsWithCloseName(name string) []*ChampionStats {
 	cs := make([]*ChampionStats, 0)
-	for c, s := range sd.ChampionsStats {
-		//printer.Debug("Similarity: %s & %s = %f", c, name, levenshtein.StringSimilarity(c, name))
-		if levenshtein.StringSimilarity(c, name) >= WeakProbability {
-			cs = append(cs, s)
-		}
+	for _, r := range sd.SearchChampions(name, 0) {
+		cs = append(cs, sd.ChampionsStats[r.Key])
 	}
 	return cs
 }
 
-func (sd *StaticData) RetrieveAPIVersion() error {
+// GetItemByName returns the Summoner's Rift item whose name matches, ignoring case.
+// Several items can share a name across maps, the lowest ID available on
+// Summoner's Rift is returned.
+func (sd *StaticData) GetItemByName(name string) (string, *ItemData) {
+	var (
+		foundID string
+		found   *ItemData
+	)
+	for id, item := range sd.ItemsData {
+		if !strings.EqualFold(item.Name, name) || !item.Maps[SummonersRiftMapID] {
+			continue
+		}
+		if found == nil || lessItemID(id, foundID) {
+			foundID, found = id, item
+		}
+	}
+	return foundID, found
+}
+
+func lessItemID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+var ErrNoAPIVersion = errors.New("no version returned by DDragon")
+
+// RetrieveAPIVersions returns every DDragon version, the latest first.
+func (sd *StaticData) RetrieveAPIVersions() ([]string, error) {
 	body, err := sd.client.Get(api.DDragonStaticVersionsURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var v []string
 	if err = json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return nil, ErrNoAPIVersion
+	}
+	return v, nil
+}
+
+func (sd *StaticData) RetrieveAPIVersion() error {
+	v, err := sd.RetrieveAPIVersions()
+	if err != nil {
 		return err
 	}
 	sd.APIVersion = v[0]
@@ -79,24 +192,40 @@ func (sd *StaticData) RetrieveChampionsStats() error {
 	for n, c := range v.Data {
 		pc := c
 		sd.ChampionsStats[n] = &pc
+		if id, err := strconv.Atoi(pc.Key); err == nil {
+			sd.championsByID[id] = &pc
+		}
 	}
+	sd.buildChampionsIndex()
 	printer.Printf("{-F_CYAN,BOLD}%d {-RESET}champions found", len(sd.ChampionsStats))
 	return nil
 }
 
-func (sd *StaticData) RetrieveItems() error {
-	body, err := sd.client.Get(sd.em.GetStaticDataItemsURL(sd.APIVersion))
+// RetrieveItemsForVersion returns the items of the given DDragon version, keyed by item ID.
+func (sd *StaticData) RetrieveItemsForVersion(version string) (map[string]*ItemData, error) {
+	body, err := sd.client.Get(sd.em.GetStaticDataItemsURL(version))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var i ItemResponse
 	if err = json.Unmarshal(body, &i); err != nil {
-		return err
+		return nil, err
 	}
+	items := make(map[string]*ItemData, len(i.Data))
 	for n, item := range i.Data {
 		pc := item
-		sd.ItemsData[n] = &pc
+		items[n] = &pc
+	}
+	return items, nil
+}
+
+func (sd *StaticData) RetrieveItems() error {
+	items, err := sd.RetrieveItemsForVersion(sd.APIVersion)
+	if err != nil {
+		return err
 	}
-	printer.Printf("{-F_CYAN,BOLD}%d {-RESET}items found", len(sd.ChampionsStats))
+	sd.ItemsData = items
+	sd.buildItemsIndex()
+	printer.Printf("{-F_CYAN,BOLD}%d {-RESET}items found", len(sd.ItemsData))
 	return nil
 }
@@ -1,9 +1,33 @@
 package gamedata
 
+// Tiers of the ranked queues, from the lowest.
 const (
-	Challenger = "CHALLENGER"
+	Iron        = "IRON"
+	Bronze      = "BRONZE"
+	Silver      = "SILVER"
+	Gold        = "GOLD"
+	Platinum    = "PLATINUM"
+	Emerald     = "EMERALD"
+	Diamond     = "DIAMOND"
+	Master      = "MASTER"
+	Grandmaster = "GRANDMASTER"
+	Challenger  = "CHALLENGER"
 )
 
+// Divisions of a tier, from the highest. The master tiers only have the
+// first.
 const (
-	TierOne = "I"
+	TierOne   = "I"
+	TierTwo   = "II"
+	TierThree = "III"
+	TierFour  = "IV"
 )
+
+// LeagueEntry is the rank of a player in a ranked queue, returned by
+// league-v4.
+type LeagueEntry struct {
+	QueueType    string `json:"queueType"`
+	Tier         string `json:"tier"`
+	Rank         string `json:"rank"`
+	LeaguePoints int    `json:"leaguePoints"`
+}
@@ -0,0 +1,88 @@
+package gamedata
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"LoLItemRecommender/internal/printer"
+)
+
+const DefaultWatchInterval = 30 * time.Minute
+
+// PatchEvent is emitted when a new DDragon version has been loaded.
+type PatchEvent struct {
+	Previous string
+	Current  string
+	Data     *StaticData
+}
+
+// Watcher polls DDragon for new versions and swaps the static data once the
+// new version is fully loaded, readers always get a complete StaticData.
+type Watcher struct {
+	current  atomic.Pointer[StaticData]
+	interval time.Duration
+	events   chan PatchEvent
+}
+
+func NewWatcher(sd *StaticData, interval time.Duration) *Watcher {
+	w := &Watcher{
+		interval: interval,
+		events:   make(chan PatchEvent, 1),
+	}
+	w.current.Store(sd)
+	return w
+}
+
+// Current returns the static data of the latest loaded version.
+func (w *Watcher) Current() *StaticData {
+	return w.current.Load()
+}
+
+// Events returns the channel on which patch changes are sent. It has to be
+// read while Run is running.
+func (w *Watcher) Events() <-chan PatchEvent {
+	return w.events
+}
+
+// check loads the static data of the latest version, if new, and sends the
+// patch event, waiting for the listener until the context is done.
+func (w *Watcher) check(ctx context.Context) error {
+	sd := w.Current()
+	versions, err := sd.RetrieveAPIVersions()
+	if err != nil {
+		return err
+	}
+	if versions[0] == sd.APIVersion {
+		return nil
+	}
+	printer.Info("New version {-F_MAGENTA,BOLD}%s{-RESET} detected, loading static data", versions[0])
+	next, err := LoadStaticData(sd.em, sd.client, versions[0])
+	if err != nil {
+		return err
+	}
+	w.current.Store(next)
+	select {
+	case w.events <- PatchEvent{Previous: sd.APIVersion, Current: next.APIVersion, Data: next}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run polls for new versions until the context is done.
+func (w *Watcher) Run(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	defer close(w.events)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := w.check(ctx); err != nil && ctx.Err() == nil {
+				printer.Error("Unable to check for a new version: %v", err)
+			}
+		}
+	}
+}
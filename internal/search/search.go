@@ -0,0 +1,130 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"LoLItemRecommender/internal/levenshtein"
+)
+
+const (
+	exactScore      = 100.0
+	prefixScore     = 90.0
+	tokenScore      = 80.0
+	minPrefixLength = 1
+)
+
+// Result is a candidate matching a query, Score going from 0 to 100.
+type Result struct {
+	Key   string
+	Name  string
+	Score float64
+}
+
+type entry struct {
+	key     string
+	name    string
+	aliases []alias
+}
+
+type alias struct {
+	compact string
+	tokens  []string
+}
+
+// Index is a set of named entries searchable with Search.
+type Index struct {
+	entries []entry
+}
+
+func NewIndex() *Index {
+	return &Index{entries: make([]entry, 0)}
+}
+
+// Add registers the entry identified by key. The name is displayed in the
+// results, the name and the aliases are all matched against the queries.
+func (idx *Index) Add(key, name string, aliases ...string) {
+	e := entry{key: key, name: name}
+	for _, a := range append([]string{name}, aliases...) {
+		n := Normalize(a)
+		if n == "" {
+			continue
+		}
+		e.aliases = append(e.aliases, alias{compact: compact(n), tokens: strings.Fields(n)})
+	}
+	idx.entries = append(idx.entries, e)
+}
+
+func score(query string, a alias) float64 {
+	q := compact(query)
+	if q == a.compact {
+		return exactScore
+	}
+	ratio := float64(utf8.RuneCountInString(q)) / float64(utf8.RuneCountInString(a.compact))
+	if utf8.RuneCountInString(q) >= minPrefixLength {
+		if strings.HasPrefix(a.compact, q) {
+			return prefixScore + (exactScore-prefixScore)*ratio*0.99
+		}
+		for _, t := range a.tokens {
+			if len(a.tokens) > 1 && strings.HasPrefix(t, query) {
+				return tokenScore + (prefixScore-tokenScore)*ratio*0.99
+			}
+		}
+	}
+	s := levenshtein.DamerauSimilarity(q, a.compact)
+	if s > tokenScore {
+		// Typos never rank above a prefix or token match.
+		s = tokenScore
+	}
+	return s
+}
+
+// Search returns the entries scoring at least minScore, the best first. Ties
+// are ordered by name so identical queries always give the same answer.
+func (idx *Index) Search(query string, minScore float64, limit int) []Result {
+	q := Normalize(query)
+	if q == "" {
+		return nil
+	}
+	results := make([]Result, 0)
+	for _, e := range idx.entries {
+		best := Result{Key: e.key, Name: e.name}
+		for _, a := range e.aliases {
+			if s := score(q, a); s > best.Score {
+				best.Score = s
+			}
+		}
+		if best.Score >= minScore {
+			results = append(results, best)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Key < results[j].Key
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// IsConfident reports whether the best result can be picked without asking
+// the user: an exact match, or a strong match clearly ahead of the next one.
+func IsConfident(results []Result, strongScore, margin float64) bool {
+	if len(results) == 0 {
+		return false
+	}
+	if results[0].Score == exactScore {
+		return len(results) == 1 || results[1].Score < exactScore
+	}
+	if results[0].Score < strongScore {
+		return false
+	}
+	return len(results) == 1 || results[0].Score-results[1].Score >= margin
+}
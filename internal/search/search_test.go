@@ -0,0 +1,114 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Kai'Sa", "kaisa"},
+		{"Bel’Veth", "belveth"},
+		{"Nunu & Willump", "nunu willump"},
+		{"Dr. Mundo", "dr mundo"},
+		{"Mejai's Soulstealer", "mejais soulstealer"},
+		{"  Jarvan   IV ", "jarvan iv"},
+		{"Héimerdinger", "heimerdinger"},
+		{"KAYN", "kayn"},
+		{"&!?", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func champions() *Index {
+	idx := NewIndex()
+	for _, n := range []string{"Kai'Sa", "Kassadin", "Katarina", "Kayle", "Kayn", "Nunu & Willump", "Dr. Mundo"} {
+		idx.Add(n, n)
+	}
+	idx.Add("MonkeyKing", "Wukong", "MonkeyKing")
+	return idx
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		// Names of the results, the best first
+		want []string
+		// Score of the best result, checked if not zero
+		score float64
+	}{
+		{"kaisa", []string{"Kai'Sa"}, exactScore},
+		{"KAI'SA", []string{"Kai'Sa"}, exactScore},
+		{"nunu willump", []string{"Nunu & Willump"}, exactScore},
+		{"nunu&willump", []string{"Nunu & Willump"}, exactScore},
+		{"monkeyking", []string{"Wukong"}, exactScore},
+		{"kai", []string{"Kai'Sa"}, 0},
+		{"willump", []string{"Nunu & Willump"}, 0},
+		{"mundo", []string{"Dr. Mundo"}, 0},
+		// Transposition and deletion, capped below the prefix matches
+		{"kiasa", []string{"Kai'Sa"}, tokenScore},
+		{"kasadin", []string{"Kassadin"}, tokenScore},
+		// Shortest names first, then ties ordered by name
+		{"ka", []string{"Kayn", "Kai'Sa", "Kayle", "Kassadin", "Katarina"}, 0},
+		{"", nil, 0},
+		{" & ", nil, 0},
+	}
+	idx := champions()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := idx.Search(tt.query, 50, len(tt.want))
+			if len(results) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, results, tt.want)
+			}
+			for i, r := range results {
+				if r.Name != tt.want[i] {
+					t.Fatalf("Search(%q) = %v, want %v", tt.query, results, tt.want)
+				}
+			}
+			if tt.score != 0 && results[0].Score != tt.score {
+				t.Errorf("Search(%q) scored %v, want %v", tt.query, results[0].Score, tt.score)
+			}
+		})
+	}
+}
+
+func TestScoreOrder(t *testing.T) {
+	a := alias{compact: "nunuwillump", tokens: []string{"nunu", "willump"}}
+	exact, prefix, token, typo := score("nunuwillump", a), score("nunu", a), score("willump", a), score("nunuwilump", a)
+	if !(exact > prefix && prefix > token && token > typo) {
+		t.Errorf("scores exact %v, prefix %v, token %v, typo %v, want them decreasing", exact, prefix, token, typo)
+	}
+}
+
+func TestIsConfident(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		want   bool
+	}{
+		{"no result", nil, false},
+		{"single exact", []float64{exactScore}, true},
+		{"exact ahead of prefix", []float64{exactScore, prefixScore}, true},
+		{"two exact", []float64{exactScore, exactScore}, false},
+		{"strong and ahead", []float64{95, 80}, true},
+		{"strong but close", []float64{95, 94}, false},
+		{"weak", []float64{70}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make([]Result, len(tt.scores))
+			for i, s := range tt.scores {
+				results[i].Score = s
+			}
+			if got := IsConfident(results, 90, 5); got != tt.want {
+				t.Errorf("IsConfident(%v) = %v, want %v", tt.scores, got, tt.want)
+			}
+		})
+	}
+}
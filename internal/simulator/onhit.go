@@ -0,0 +1,65 @@
+package simulator
+
+import (
+	"LoLItemRecommender/internal/calculator"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// Champions with an attack range above this limit are considered ranged.
+const meleeRangeLimit = 300
+
+// OnHit is the damage applied by every auto attack on top of the attack damage.
+type OnHit struct {
+	Physical float64
+	Magic    float64
+	True     float64
+	// Ratio of the target current HP dealt as physical damage.
+	TargetCurrentHP float64
+}
+
+func (o *OnHit) add(other OnHit) {
+	o.Physical += other.Physical
+	o.Magic += other.Magic
+	o.True += other.True
+	o.TargetCurrentHP += other.TargetCurrentHP
+}
+
+// DDragon doesn't expose on-hit effects, so the most common ones are kept
+// here, keyed by item name. Level-dependent values are interpolated and
+// effects procing every few attacks are averaged per attack.
+var onHitEffects = map[string]func(s *calculator.Sheet, ranged bool) OnHit{
+	"Blade of The Ruined King": func(s *calculator.Sheet, ranged bool) OnHit {
+		if ranged {
+			return OnHit{TargetCurrentHP: 0.06}
+		}
+		return OnHit{TargetCurrentHP: 0.09}
+	},
+	"Wit's End": func(s *calculator.Sheet, _ bool) OnHit {
+		return OnHit{Magic: 15 + 65*float64(s.Level-1)/float64(calculator.MaxLevel-1)}
+	},
+	"Nashor's Tooth": func(s *calculator.Sheet, _ bool) OnHit {
+		return OnHit{Magic: 15 + 0.2*s.AbilityPower}
+	},
+	"Guinsoo's Rageblade": func(_ *calculator.Sheet, _ bool) OnHit {
+		return OnHit{Magic: 30}
+	},
+	"Terminus": func(_ *calculator.Sheet, _ bool) OnHit {
+		return OnHit{Magic: 30}
+	},
+	"Recurve Bow": func(_ *calculator.Sheet, _ bool) OnHit {
+		return OnHit{Physical: 15}
+	},
+	"Kraken Slayer": func(s *calculator.Sheet, _ bool) OnHit {
+		return OnHit{True: (60 + 0.45*s.BonusAttackDamage) / 3}
+	},
+}
+
+func onHitFor(items []*gamedata.ItemData, s *calculator.Sheet, ranged bool) OnHit {
+	var o OnHit
+	for _, item := range items {
+		if f, ok := onHitEffects[item.Name]; ok {
+			o.add(f(s, ranged))
+		}
+	}
+	return o
+}
@@ -0,0 +1,144 @@
+package simulator
+
+import (
+	"math"
+
+	"LoLItemRecommender/internal/calculator"
+	"LoLItemRecommender/internal/riotapi/gamedata"
+)
+
+// BurstWindow is the duration over which the burst damage is measured.
+const BurstWindow = 3.0
+
+// Target is the defensive profile the build is simulated against.
+type Target struct {
+	HP          float64
+	Armor       float64
+	MagicResist float64
+}
+
+// Result holds the theoretical output of a build against a target.
+type Result struct {
+	Sheet *calculator.Sheet
+	// Damage dealt per auto attack, crits being averaged on the crit chance.
+	DamagePerHit float64
+	DPS          float64
+	// Damage dealt by the auto attacks landed during the BurstWindow.
+	Burst float64
+	// Seconds needed to kill the target with auto attacks only.
+	TimeToKill float64
+	// Effective HP of the simulated champion against physical and magic damage.
+	PhysicalEHP float64
+	MagicEHP    float64
+}
+
+// damageMultiplier returns the ratio of damage going through the given resistance.
+func damageMultiplier(resistance float64) float64 {
+	if resistance >= 0 {
+		return 100 / (100 + resistance)
+	}
+	return 2 - 100/(100-resistance)
+}
+
+// effectiveResistance applies the percentage penetration then the flat one.
+// Penetration can't bring the resistance below 0.
+func effectiveResistance(resistance, percentPen, flatPen float64) float64 {
+	if resistance <= 0 {
+		return resistance
+	}
+	r := resistance * (1 - percentPen)
+	return math.Max(r-flatPen, 0)
+}
+
+// Simulate estimates the auto attack damage and the effective HP of the
+// champion at the given level and items against the target.
+func Simulate(champion *gamedata.ChampionStats, level int, items []*gamedata.ItemData, target Target) (*Result, error) {
+	s, err := calculator.ComputeSheet(champion, level, items)
+	if err != nil {
+		return nil, err
+	}
+	armor := effectiveResistance(target.Armor, s.ArmorPenetration, s.Lethality)
+	mr := effectiveResistance(target.MagicResist, s.MagicPenPercent, s.MagicPenetration)
+
+	onHit := onHitFor(items, s, champion.Stats.Attackrange > meleeRangeLimit)
+	critMultiplier := 1 + s.CritChance*(s.CritDamage-1)
+	physical := (s.AttackDamage*critMultiplier + onHit.Physical + onHit.TargetCurrentHP*target.HP) * damageMultiplier(armor)
+	magic := onHit.Magic * damageMultiplier(mr)
+
+	r := &Result{
+		Sheet:        s,
+		DamagePerHit: physical + magic + onHit.True,
+		PhysicalEHP:  s.HP / damageMultiplier(s.Armor),
+		MagicEHP:     s.HP / damageMultiplier(s.MagicResist),
+	}
+	r.DPS = r.DamagePerHit * s.AttackSpeed
+	r.Burst = r.DamagePerHit * math.Ceil(s.AttackSpeed*BurstWindow)
+	if r.DPS > 0 {
+		r.TimeToKill = target.HP / r.DPS
+	}
+	return r, nil
+}
+
+// TargetFromChampions builds the average profile of the given champions at
+// the given level, without items.
+func TargetFromChampions(champions []*gamedata.ChampionStats, level int) (Target, error) {
+	var t Target
+	if len(champions) == 0 {
+		return t, nil
+	}
+	for _, c := range champions {
+		s, err := calculator.ComputeSheet(c, level, nil)
+		if err != nil {
+			return t, err
+		}
+		t.HP += s.HP
+		t.Armor += s.Armor
+		t.MagicResist += s.MagicResist
+	}
+	n := float64(len(champions))
+	t.HP /= n
+	t.Armor /= n
+	t.MagicResist /= n
+	return t, nil
+}
+
+// Frontline returns the tanks and fighters among the champions, or all of
+// them if there is none.
+func Frontline(champions []*gamedata.ChampionStats) []*gamedata.ChampionStats {
+	f := make([]*gamedata.ChampionStats, 0, len(champions))
+	for _, c := range champions {
+		for _, tag := range c.Tags {
+			if tag == "Tank" || tag == "Fighter" {
+				f = append(f, c)
+				break
+			}
+		}
+	}
+	if len(f) == 0 {
+		return champions
+	}
+	return f
+}
+
+// CompareDPS returns by how much percent the DPS with the item a is higher
+// than the DPS with the item b, both being added to the base build.
+func CompareDPS(champion *gamedata.ChampionStats, level int, base []*gamedata.ItemData, a, b *gamedata.ItemData, target Target) (float64, error) {
+	ra, err := Simulate(champion, level, withItem(base, a), target)
+	if err != nil {
+		return 0, err
+	}
+	rb, err := Simulate(champion, level, withItem(base, b), target)
+	if err != nil {
+		return 0, err
+	}
+	if rb.DPS == 0 {
+		return 0, nil
+	}
+	return (ra.DPS/rb.DPS - 1) * 100, nil
+}
+
+func withItem(base []*gamedata.ItemData, item *gamedata.ItemData) []*gamedata.ItemData {
+	items := make([]*gamedata.ItemData, 0, len(base)+1)
+	items = append(items, base...)
+	return append(items, item)
+}
@@ -2,25 +2,30 @@ package style
 
 import (
 	"strings"
+	"unicode"
 )
 
+// ToTitleCase capitalizes the first letter of every word, any rune that is not
+// a letter separating words: "kai'sa" gives "Kai'Sa" and "nunu & willump"
+// gives "Nunu & Willump".
 func ToTitleCase(s string) string {
-	var titleCase string
+	var titleCase strings.Builder
 	capitalizeNext := true
 
 	for _, c := range s {
-		if c == ' ' {
+		if !unicode.IsLetter(c) {
 			capitalizeNext = true
+			titleCase.WriteRune(c)
 			continue
 		}
 
 		if capitalizeNext {
-			titleCase += strings.ToUpper(string(c))
+			titleCase.WriteRune(unicode.ToUpper(c))
 			capitalizeNext = false
 		} else {
-			titleCase += strings.ToLower(string(c))
+			titleCase.WriteRune(unicode.ToLower(c))
 		}
 	}
 
-	return titleCase
+	return titleCase.String()
 }
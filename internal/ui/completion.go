@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"LoLItemRecommender/internal/riotapi/gamedata"
+	"LoLItemRecommender/internal/search"
+)
+
+var commands = []string{"end", "clear", "display", "show", "search", "compare", "target", BlueTeam, RedTeam}
+
+type candidate struct {
+	name       string
+	normalized string
+}
+
+// completer completes commands, champion and item names. Item lists being
+// comma separated, only the text after the last comma is completed.
+type completer struct {
+	candidates []candidate
+}
+
+func newCompleter(sd *gamedata.StaticData) *completer {
+	names := make(map[string]bool)
+	for _, c := range commands {
+		names[c] = true
+	}
+	for _, c := range sd.ChampionsStats {
+		names[c.Name] = true
+	}
+	for _, item := range sd.ItemsData {
+		if item.IsPurchasable(gamedata.SummonersRiftMapID) {
+			names[item.Name] = true
+		}
+	}
+	c := &completer{candidates: make([]candidate, 0, len(names))}
+	for n := range names {
+		c.candidates = append(c.candidates, candidate{name: n, normalized: search.Normalize(n)})
+	}
+	sort.Slice(c.candidates, func(i, j int) bool {
+		return c.candidates[i].name < c.candidates[j].name
+	})
+	return c
+}
+
+func (c *completer) complete(line string, pos int) (head string, completions []string, tail string) {
+	// pos is an index in runes
+	r := []rune(line)
+	word := string(r[:pos])
+	tail = string(r[pos:])
+	if i := strings.LastIndex(word, ","); i >= 0 {
+		head, word = word[:i+1]+" ", strings.TrimSpace(word[i+1:])
+	}
+	prefix := search.Normalize(word)
+	if prefix == "" {
+		return head, nil, tail
+	}
+	for _, cd := range c.candidates {
+		if strings.HasPrefix(cd.normalized, prefix) {
+			completions = append(completions, cd.name)
+		}
+	}
+	return head, completions, tail
+}
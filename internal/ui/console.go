@@ -1,23 +1,39 @@
 package ui
 
 import (
-	"bufio"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"LoLItemRecommender/internal/database"
 	"LoLItemRecommender/internal/printer"
 	"LoLItemRecommender/internal/riotapi/gamedata"
-	"LoLItemRecommender/internal/style"
+	"LoLItemRecommender/internal/search"
+	"LoLItemRecommender/internal/simulator"
+	"github.com/peterh/liner"
 )
 
 type Console struct {
-	blueTeam []*gamedata.ChampionStats
-	redTeam  []*gamedata.ChampionStats
-	reader   *bufio.Reader
-	quit     chan struct{}
+	blueTeam    []*gamedata.ChampionStats
+	redTeam     []*gamedata.ChampionStats
+	target      *gamedata.ChampionStats
+	line        *liner.State
+	historyPath string
+	quit        chan struct{}
+	quitOnce    *sync.Once
+	// Line requests and the lines read by the prompt loop
+	requests chan struct{}
+	inputs   chan string
+	// Closed when the prompt loop returns
+	done      chan struct{}
+	prompting atomic.Bool
 }
 
 const (
@@ -25,28 +41,117 @@ const (
 	RedTeam  = "red"
 )
 
+const (
+	historyFileName = ".lol-item-recommender_history"
+	prompt          = "> "
+)
+
 func NewConsole(ctx context.Context) *Console {
 	c := &Console{
 		blueTeam: make([]*gamedata.ChampionStats, 0),
 		redTeam:  make([]*gamedata.ChampionStats, 0),
-		reader:   bufio.NewReader(os.Stdin),
+		line:     liner.NewLiner(),
 		quit:     make(chan struct{}),
+		quitOnce: &sync.Once{},
+		requests: make(chan struct{}),
+		inputs:   make(chan string),
+		done:     make(chan struct{}),
+	}
+	c.line.SetCtrlCAborts(true)
+	c.line.SetTabCompletionStyle(liner.TabPrints)
+	if home, err := os.UserHomeDir(); err == nil {
+		c.historyPath = filepath.Join(home, historyFileName)
+		c.readHistory()
 	}
 	go func(ctx context.Context, c *Console) {
 		select {
 		case <-ctx.Done():
-			close(c.quit)
+			c.stop()
 		}
 	}(ctx, c)
+	go c.promptLoop()
 	return c
 }
 
+func (c *Console) stop() {
+	c.quitOnce.Do(func() {
+		close(c.quit)
+	})
+}
+
+func (c *Console) readHistory() {
+	f, err := os.Open(c.historyPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			printer.Warn("Unable to read the history: %v", err)
+		}
+		return
+	}
+	defer f.Close()
+	if _, err := c.line.ReadHistory(f); err != nil {
+		printer.Warn("Unable to read the history: %v", err)
+	}
+}
+
+// Close stops the console, saves the history and restores the terminal once
+// the prompt loop has returned.
+func (c *Console) Close() error {
+	c.stop()
+	if c.prompting.Load() {
+		// The prompt can't be interrupted
+		printer.Print("Press {-BOLD}Enter{-RESET} to quit")
+	}
+	<-c.done
+	if c.historyPath != "" {
+		if f, err := os.Create(c.historyPath); err != nil {
+			printer.Warn("Unable to save the history: %v", err)
+		} else {
+			if _, err := c.line.WriteHistory(f); err != nil {
+				printer.Warn("Unable to save the history: %v", err)
+			}
+			f.Close()
+		}
+	}
+	return c.line.Close()
+}
+
+// SetTarget changes the champion the item suggestions are made for.
+func (c *Console) SetTarget(champion *gamedata.ChampionStats) {
+	c.target = champion
+}
+
+var ErrNoTarget = errors.New("no target champion, type 'target' to choose one")
+
+func (c *Console) displayTarget() {
+	if c.target == nil {
+		printer.Print("No target champion, type {-BOLD}'target'{-RESET} to choose the champion to get item suggestions for")
+		return
+	}
+	printer.Print("Getting item suggestions for {-F_YELLOW,BOLD}" + c.target.Name + "{-RESET} based on the game composition, type {-BOLD}'target'{-RESET} to change it")
+}
+
+// AskTarget asks the champion the item suggestions are made for.
+func (c *Console) AskTarget(sd *gamedata.StaticData) error {
+	input, err := c.ask("For which champion do you want item suggestions ?")
+	if err != nil {
+		return err
+	}
+	r, err := resolve(sd.SearchChampions(input, maxSuggestions), input)
+	if err != nil {
+		return err
+	}
+	c.SetTarget(sd.ChampionsStats[r.Key])
+	c.displayTarget()
+	return nil
+}
+
 func (c *Console) DisplayInstructions() {
 	printer.Printf("{-F_CYAN,BOLD}════════════════════════════════════════════════")
 	printer.Printf("{-F_CYAN,BOLD}       ItemResponse Advisor - League of Legends       ")
 	printer.Printf("{-F_CYAN,BOLD}════════════════════════════════════════════════")
 
-	printer.Print("Follow the instructions to get item suggestions for Samira based on the game composition")
+	c.displayTarget()
+	printer.Print("Type {-BOLD}'compare'{-RESET} to compare the theoretical damage of two items against the red team")
 	printer.Print("--------------------------------------------------------")
 }
 
@@ -58,19 +163,155 @@ func (c *Console) AddChampionToTeam(team string, champion *gamedata.ChampionStat
 	}
 }
 
+// promptLoop reads a line each time one is requested, until the console is
+// stopped. It owns the liner state, which is closed once it returns.
+func (c *Console) promptLoop() {
+	defer close(c.done)
+	for {
+		select {
+		case <-c.requests:
+		case <-c.quit:
+			return
+		}
+		c.prompting.Store(true)
+		input, err := c.line.Prompt(prompt)
+		c.prompting.Store(false)
+		if err != nil {
+			if err != liner.ErrPromptAborted && err != io.EOF {
+				printer.PrintError(err)
+			}
+			// Ctrl-C and Ctrl-D leave the console
+			c.stop()
+			return
+		}
+		input = strings.TrimSpace(input)
+		if input != "" {
+			c.line.AppendHistory(input)
+		}
+		select {
+		case c.inputs <- input:
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// readNonBlockingInput asks the prompt loop for a line, sent on the returned
+// channel. The callers wait for it or for the console to stop.
 func (c *Console) readNonBlockingInput() <-chan string {
-	inputChan := make(chan string)
-	go func() {
-		input, err := c.reader.ReadString('\n')
+	select {
+	case c.requests <- struct{}{}:
+	case <-c.quit:
+	}
+	return c.inputs
+}
+
+var (
+	ErrContextCanceled = errors.New("context canceled")
+	ErrNoEnemyTeam     = errors.New("the red team is empty, add the enemy champions first")
+)
+
+func (c *Console) ask(question string) (string, error) {
+	printer.Print(question)
+	select {
+	case input := <-c.readNonBlockingInput():
+		return input, nil
+	case <-c.quit:
+		return "", ErrContextCanceled
+	}
+}
+
+func (c *Console) askItems(sd *gamedata.StaticData, question string) ([]*gamedata.ItemData, error) {
+	input, err := c.ask(question)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*gamedata.ItemData, 0)
+	for _, name := range strings.Split(input, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		r, err := resolve(sd.SearchItems(name, maxSuggestions), name)
 		if err != nil {
-			printer.PrintError(err)
+			return nil, err
 		}
-		inputChan <- strings.TrimSpace(input)
-	}()
-	return inputChan
+		items = append(items, sd.ItemsData[r.Key])
+	}
+	return items, nil
+}
+
+const maxSuggestions = 3
+
+// resolve picks the best search result, or returns an error suggesting the
+// closest names when the query is ambiguous.
+func resolve(results []search.Result, query string) (search.Result, error) {
+	if len(results) == 0 {
+		return search.Result{}, fmt.Errorf("'%s' doesn't exist", query)
+	}
+	if search.IsConfident(results, gamedata.StrongProbability, gamedata.ConfidenceMargin) {
+		return results[0], nil
+	}
+	names := make([]string, 0, len(results))
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	suggestions := names[0]
+	if len(names) > 1 {
+		suggestions = strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+	}
+	return search.Result{}, fmt.Errorf("'%s' is ambiguous, did you mean %s?", query, suggestions)
 }
 
-var ErrContextCanceled = errors.New("context canceled")
+// CompareItems simulates the tracked champion against the red team frontline
+// with two different items and displays the DPS difference.
+func (c *Console) CompareItems(sd *gamedata.StaticData) error {
+	if len(c.redTeam) == 0 {
+		return ErrNoEnemyTeam
+	}
+	if c.target == nil {
+		return ErrNoTarget
+	}
+	champion := c.target
+	input, err := c.ask("At which level ?")
+	if err != nil {
+		return err
+	}
+	level, err := strconv.Atoi(input)
+	if err != nil {
+		return err
+	}
+	base, err := c.askItems(sd, "Which items are already bought ? (comma separated, empty for none)")
+	if err != nil {
+		return err
+	}
+	compared, err := c.askItems(sd, "Which two items do you want to compare ? (comma separated)")
+	if err != nil {
+		return err
+	}
+	if len(compared) != 2 {
+		return fmt.Errorf("expected 2 items to compare, got %d", len(compared))
+	}
+	target, err := simulator.TargetFromChampions(simulator.Frontline(c.redTeam), level)
+	if err != nil {
+		return err
+	}
+	a, b := compared[0], compared[1]
+	diff, err := simulator.CompareDPS(champion, level, base, a, b, target)
+	if err != nil {
+		return err
+	}
+	printer.Printf("Enemy frontline at level %d: {-BOLD}%.0f{-RESET} HP, {-BOLD}%.0f{-RESET} armor, {-BOLD}%.0f{-RESET} magic resist", level, target.HP, target.Armor, target.MagicResist)
+	for _, item := range compared {
+		r, err := simulator.Simulate(champion, level, append(base[:len(base):len(base)], item), target)
+		if err != nil {
+			return err
+		}
+		printer.Printf("{-F_YELLOW}%s{-RESET}: %.0f DPS, %.0f burst, killed in %.1fs", item.Name, r.DPS, r.Burst, r.TimeToKill)
+	}
+	printer.Printf("vs. this enemy frontline, {-F_GREEN,BOLD}%s{-RESET} gives {-BOLD}%+.1f%%{-RESET} damage over {-F_YELLOW,BOLD}%s", a.Name, diff, b.Name)
+	return nil
+}
 
 func (c *Console) AskForChampionTeam(champion *gamedata.ChampionStats) error {
 	for {
@@ -109,6 +350,7 @@ func (c *Console) Display() {
 }
 
 func (c *Console) AskUserChampions(sd *gamedata.StaticData, db *database.DB) {
+	c.line.SetWordCompleter(newCompleter(sd).complete)
 	c.DisplayInstructions()
 	for {
 		printer.Print("Please enter the names of the champions for which you want item suggestions. Type {-BOLD}'end'{-RESET} to finish entering.")
@@ -140,25 +382,41 @@ func (c *Console) AskUserChampions(sd *gamedata.StaticData, db *database.DB) {
 					printer.Error("not enough participant to make a search")
 					continue
 				}
-				p, err := db.GetMatchesWithChampions(c.blueTeam, c.redTeam)
+				if c.target == nil {
+					printer.PrintError(ErrNoTarget)
+					continue
+				}
+				p, err := db.GetMatchesWithChampions(c.target, c.blueTeam, c.redTeam)
 				if err != nil {
 					printer.PrintError(err)
 					return
 				}
-				printer.Debug("%v participants found like %d matches", len(p), len(p)/10)
+				printer.Debug("%d matches found with %s", len(p), c.target.Name)
+				continue
+			case "target":
+				if err := c.AskTarget(sd); err != nil {
+					if err == ErrContextCanceled {
+						return
+					}
+					printer.PrintError(err)
+				}
+				continue
+			case "compare":
+				if err := c.CompareItems(sd); err != nil {
+					if err == ErrContextCanceled {
+						return
+					}
+					printer.PrintError(err)
+				}
 				continue
 			}
 
-			input = style.ToTitleCase(input)
-
-			if input == "Wukong" { // Riot API call the champion Wukong: "MonkeyKing"
-				input = "MonkeyKing"
-			}
-			s := sd.GetChampionStats(input)
-			if s == nil {
-				printer.Error("'%s' doesn't exist", input)
+			r, err := resolve(sd.SearchChampions(input, maxSuggestions), input)
+			if err != nil {
+				printer.PrintError(err)
 				continue
 			}
+			s := sd.ChampionsStats[r.Key]
 			printer.Printf("Champion '%s' found", s.Name)
 			if err := c.AskForChampionTeam(s); err != nil && err == ErrContextCanceled {
 				return